@@ -1,18 +1,25 @@
 package client
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 
 	"github.com/ovn-org/libovsdb/ovsdb"
 )
 
 const (
-	opInsert string = "insert"
-	opMutate string = "mutate"
-	opUpdate string = "insert"
-	opDelete string = "delete"
+	opInsert  string = "insert"
+	opMutate  string = "mutate"
+	opUpdate  string = "update"
+	opDelete  string = "delete"
+	opSelect  string = "select"
+	opWait    string = "wait"
+	opComment string = "comment"
+	opAssert  string = "assert"
+	opAbort   string = "abort"
 )
 
 // API defines basic operations to interact with the database
@@ -21,6 +28,7 @@ type API interface {
 	// The function parameter must be a pointer to a slice of Models
 	// If the slice is null, the entire cache will be copied into the slice
 	// If it has a capacity != 0, only 'capacity' elements will be filled in
+	// The models are deep copies of the cached ones, so they can be modified freely
 	List(result interface{}) error
 
 	// Create a Conditional API from a Function that is used to filter cached data
@@ -31,18 +39,27 @@ type API interface {
 	// Create a ConditionalAPI from a Model's index data or a list of Conditions
 	// where operations apply to elements that match any of the conditions
 	// If no condition is given, it will match the values provided in Model according
-	// to the database index.
+	// to the database index
 	Where(Model, ...Condition) ConditionalAPI
 
 	// Create a ConditionalAPI from a Model's index data or a list of Conditions
 	// where operations apply to elements that match all the conditions
 	WhereAll(Model, ...Condition) ConditionalAPI
 
+	// Create a ConditionalAPI from a Model's index data, as Where does, whose Update,
+	// UpdateDiff and Mutate operations are preceded by a wait operation that makes the
+	// transaction fail if the row no longer has the values of the Model, usually read
+	// from the cache. Optional fields (pointers to fields in the model) restrict the
+	// columns compared. See OvsdbClient.RetryTransaction
+	WhereUnchanged(Model, ...interface{}) ConditionalAPI
+
 	// Get retrieves a model from the cache
 	// The way the object will be fetch depends on the data contained in the
-	// provided model and the indexes defined in the associated schema
+	// provided model and the indexes defined in the associated schema, as well
+	// as the client indexes of the DBModel
 	// For more complex ways of searching for elements in the cache, the
 	// preferred way is Where({condition}).List()
+	// The model is filled in with a deep copy of the cached one
 	Get(Model) error
 
 	// Create returns the operation needed to add the model(s) to the Database
@@ -50,6 +67,31 @@ type API interface {
 	// If the field associated with column "_uuid" has some content, it will be
 	// treated as named-uuid
 	Create(...Model) ([]ovsdb.Operation, error)
+
+	// Upsert returns the operations needed to create the model or, if the cache has a row
+	// with the same values in an index of the schema, to update it with the values of the
+	// model (or of the optional fields), guarded by a wait operation that makes the
+	// transaction fail if the row was created or deleted meanwhile
+	Upsert(model Model, fields ...interface{}) ([]ovsdb.Operation, error)
+
+	// References populates a slice of Models with the rows of the cache referenced by
+	// a field of the model (pointer to the field), which must be a column with a refTable
+	// References to rows that are not in the cache are skipped
+	References(model Model, field interface{}, result interface{}) error
+
+	// ReferencedBy populates a slice of Models with the rows of their table that reference
+	// the model, which is looked up in the cache as Get does. E.g: the Bridges of a Port
+	// ReferencedBy(&Port{UUID: uuid}, &bridges)
+	ReferencedBy(model Model, result interface{}) error
+
+	// Comment returns the operation that adds a comment, logged by the server, to a transaction
+	Comment(comment string) ([]ovsdb.Operation, error)
+
+	// Assert returns the operation that makes a transaction fail unless the client owns the lock
+	Assert(lock string) ([]ovsdb.Operation, error)
+
+	// Abort returns the operation that makes a transaction fail
+	Abort() ([]ovsdb.Operation, error)
 }
 
 // ConditionalAPI is an interface used to perform operations that require / use Conditions
@@ -61,6 +103,8 @@ type ConditionalAPI interface {
 	// Mutate returns the operations needed to perform the mutation specified
 	// By the model and the list of Mutation objects
 	// Depending on the Condition, it might return one or many operations
+	// Mutations of columns that are not mutable fail with ErrImmutableColumn,
+	// or are left out if the client is created WithSkipImmutableColumns
 	Mutate(Model, []Mutation) ([]ovsdb.Operation, error)
 
 	// Update returns the operations needed to update any number of rows according
@@ -68,10 +112,35 @@ type ConditionalAPI interface {
 	// By default, all the non-default values contained in model will be updated.
 	// Optional fields can be passed (pointer to fields in the model) to select the
 	// the fields to be updated
+	// Columns that are not mutable are handled as in Mutate
 	Update(Model, ...interface{}) ([]ovsdb.Operation, error)
 
+	// UpdateDiff returns the operations needed to turn the old model (e.g: a copy of the
+	// cached one) into the new one, writing only the columns that differ. Sets and maps
+	// are mutated element by element so that concurrent changes to other elements are kept
+	// Changes to columns that are not mutable are handled as in Mutate. Ephemeral columns
+	// the old model has no value for are left out while the table holds a snapshot
+	UpdateDiff(oldModel, newModel Model) ([]ovsdb.Operation, error)
+
 	// Delete returns the Operations needed to delete the models seleted via the condition
 	Delete() ([]ovsdb.Operation, error)
+
+	// Select runs a select operation on the server and populates a slice of Models
+	// with the rows that match the condition, whether the table is monitored or not
+	// Optional fields (pointers to fields of the model given to Where or WhereAll)
+	// can be passed to only retrieve their columns
+	Select(ctx context.Context, result interface{}, fields ...interface{}) error
+
+	// SelectOperations returns the operations Select runs, to be part of a transaction
+	SelectOperations(fields ...interface{}) ([]ovsdb.Operation, error)
+
+	// Wait returns the operations that make a transaction wait, up to timeout milliseconds,
+	// until the rows that match the condition are equal (or not) to the model. Optional
+	// fields (pointers to fields in the model) select the columns compared
+	Wait(until ovsdb.WaitCondition, timeout int, model Model, fields ...interface{}) ([]ovsdb.Operation, error)
+
+	// Count returns the number of rows in the database that match the condition
+	Count(ctx context.Context) (int, error)
 }
 
 // Mutation is a type that represents a OVSDB Mutation
@@ -120,11 +189,45 @@ func (c ConditionError) String() string {
 // ErrNotFound is used to inform the object or table was not found in the cache
 var ErrNotFound = errors.New("object not found")
 
+// ErrImmutableColumn is returned when an operation would modify a column that is not mutable
+type ErrImmutableColumn struct {
+	table  string
+	column string
+}
+
+func (e *ErrImmutableColumn) Error() string {
+	return fmt.Sprintf("column %s of table %s is not mutable", e.column, e.table)
+}
+
+// Table returns the name of the table of the column
+func (e *ErrImmutableColumn) Table() string {
+	return e.table
+}
+
+// Column returns the name of the column that is not mutable
+func (e *ErrImmutableColumn) Column() string {
+	return e.column
+}
+
+// NewErrImmutableColumn creates a new ErrImmutableColumn
+func NewErrImmutableColumn(table, column string) error {
+	return &ErrImmutableColumn{
+		table:  table,
+		column: column,
+	}
+}
+
 // api struct implements both API and ConditionalAPI
 // Where() can be used to create a ConditionalAPI api
 type api struct {
-	cache *TableCache
-	cond  Conditional
+	cache    *TableCache
+	cond     Conditional
+	transact func(context.Context, ...ovsdb.Operation) ([]ovsdb.OperationResult, error)
+	// wait, if set, is checked before Update, UpdateDiff and Mutate operations
+	wait *waitCondition
+	// skipImmutable makes Update, UpdateDiff and Mutate leave out the columns that are not
+	// mutable instead of failing with ErrImmutableColumn
+	skipImmutable bool
 }
 
 // List populates a slice of Models given as parameter based on the configured Condition
@@ -161,13 +264,29 @@ func (a api) List(result interface{}) error {
 	}
 	i := resultVal.Len()
 
-	for _, row := range tableCache.Rows() {
+	// Conditionals that can use the indexes of the cache only need to look at the rows they return
+	rows := tableCache.Rows()
+	indexed := false
+	if lookup, ok := a.cond.(indexedConditional); ok {
+		var err error
+		if rows, indexed, err = lookup.rows(tableCache); err != nil {
+			return err
+		}
+		if !indexed {
+			rows = tableCache.Rows()
+		}
+	}
+
+	for _, row := range rows {
 		elem := tableCache.Row(row)
+		if elem == nil {
+			continue
+		}
 		if i >= resultVal.Cap() {
 			break
 		}
 
-		if a.cond != nil {
+		if a.cond != nil && !indexed {
 			if matches, err := a.cond.Matches(elem); err != nil {
 				return err
 			} else if !matches {
@@ -175,7 +294,7 @@ func (a api) List(result interface{}) error {
 			}
 		}
 
-		resultVal.Set(reflect.Append(resultVal, reflect.Indirect(reflect.ValueOf(elem))))
+		resultVal.Set(reflect.Append(resultVal, deepCopy(reflect.Indirect(reflect.ValueOf(elem)))))
 		i++
 	}
 	return nil
@@ -183,17 +302,17 @@ func (a api) List(result interface{}) error {
 
 // Where returns a conditionalAPI based on a Condition list
 func (a api) Where(model Model, cond ...Condition) ConditionalAPI {
-	return newConditionalAPI(a.cache, a.conditionFromModel(false, model, cond...))
+	return newConditionalAPI(a, a.conditionFromModel(false, model, cond...))
 }
 
 // Where returns a conditionalAPI based on a Condition list
 func (a api) WhereAll(model Model, cond ...Condition) ConditionalAPI {
-	return newConditionalAPI(a.cache, a.conditionFromModel(true, model, cond...))
+	return newConditionalAPI(a, a.conditionFromModel(true, model, cond...))
 }
 
 // Where returns a conditionalAPI based a Predicate
 func (a api) WhereCache(predicate interface{}) ConditionalAPI {
-	return newConditionalAPI(a.cache, a.conditionFromFunc(predicate))
+	return newConditionalAPI(a, a.conditionFromFunc(predicate))
 }
 
 // Conditional interface implementation
@@ -253,6 +372,23 @@ func (a api) Get(model Model) error {
 		return ErrNotFound
 	}
 
+	// Use the indexes of the cache, if available
+	rows, indexed, err := tableCache.rowsByModel(model, true)
+	if err != nil {
+		return err
+	}
+	if indexed {
+		if len(rows) == 0 {
+			return ErrNotFound
+		}
+		found := tableCache.Row(rows[0])
+		if found == nil {
+			return ErrNotFound
+		}
+		reflect.ValueOf(model).Elem().Set(deepCopy(reflect.Indirect(reflect.ValueOf(found))))
+		return nil
+	}
+
 	// If model contains _uuid value, we can access it via cache index
 	ormInfo, err := newORMInfo(a.cache.orm.schema.Table(table), model)
 	if err != nil {
@@ -262,7 +398,7 @@ func (a api) Get(model Model) error {
 		if found := tableCache.Row(uuid.(string)); found == nil {
 			return ErrNotFound
 		} else {
-			reflect.ValueOf(model).Elem().Set(reflect.Indirect(reflect.ValueOf(found)))
+			reflect.ValueOf(model).Elem().Set(deepCopy(reflect.Indirect(reflect.ValueOf(found))))
 			return nil
 		}
 	}
@@ -275,7 +411,7 @@ func (a api) Get(model Model) error {
 			return err
 		}
 		if equal {
-			reflect.ValueOf(model).Elem().Set(reflect.Indirect(reflect.ValueOf(elem)))
+			reflect.ValueOf(model).Elem().Set(deepCopy(reflect.Indirect(reflect.ValueOf(elem))))
 			return nil
 		}
 	}
@@ -350,6 +486,11 @@ func (a api) Mutate(model Model, mutationObjs []Mutation) ([]ovsdb.Operation, er
 		if err != nil {
 			return nil, err
 		}
+		if writable, err := a.writableColumn(tableName, table, col); err != nil {
+			return nil, err
+		} else if !writable {
+			continue
+		}
 
 		mutation, err := a.cache.orm.newMutation(tableName, model, col, mobj.Mutator, mobj.Value)
 		if err != nil {
@@ -357,7 +498,12 @@ func (a api) Mutate(model Model, mutationObjs []Mutation) ([]ovsdb.Operation, er
 		}
 		mutations = append(mutations, mutation)
 	}
+	if len(mutations) == 0 && len(mutationObjs) > 0 {
+		// every mutation was of an immutable column
+		return operations, nil
+	}
 	for _, condition := range conditions {
+		operations = append(operations, a.waitOperations(condition)...)
 		operations = append(operations,
 			ovsdb.Operation{
 				Op:        opMutate,
@@ -389,8 +535,14 @@ func (a api) Update(model Model, fields ...interface{}) ([]ovsdb.Operation, erro
 	if err != nil {
 		return nil, err
 	}
+	if skipped, err := a.removeImmutable(table, row); err != nil {
+		return nil, err
+	} else if skipped && len(row) == 0 {
+		return operations, nil
+	}
 
 	for _, condition := range conditions {
+		operations = append(operations, a.waitOperations(condition)...)
 		operations = append(operations,
 			ovsdb.Operation{
 				Op:    opUpdate,
@@ -424,6 +576,46 @@ func (a api) Delete() ([]ovsdb.Operation, error) {
 	return operations, nil
 }
 
+// writableColumn returns whether an operation can modify the column. Columns that are not
+// mutable make it fail with ErrImmutableColumn unless the API skips them
+func (a api) writableColumn(table string, tableSchema *ovsdb.TableSchema, column string) (bool, error) {
+	columnSchema, ok := tableSchema.Columns[column]
+	if !ok || columnSchema.Mutable() {
+		return true, nil
+	}
+	if a.skipImmutable {
+		return false, nil
+	}
+	return false, NewErrImmutableColumn(table, column)
+}
+
+// removeImmutable removes from the row to update the columns that are not mutable and
+// returns whether it removed any, see writableColumn
+func (a api) removeImmutable(table string, row map[string]interface{}) (bool, error) {
+	tableSchema := a.cache.orm.schema.Table(table)
+	if tableSchema == nil {
+		return false, NewErrNoTable(table)
+	}
+	columns := make([]string, 0, len(row))
+	for column := range row {
+		columns = append(columns, column)
+	}
+	// report the same column every time
+	sort.Strings(columns)
+	removed := false
+	for _, column := range columns {
+		writable, err := a.writableColumn(table, tableSchema, column)
+		if err != nil {
+			return false, err
+		}
+		if !writable {
+			delete(row, column)
+			removed = true
+		}
+	}
+	return removed, nil
+}
+
 // getTableFromModel returns the table name from a Model object after performing
 // type verifications on the model
 func (a api) getTableFromModel(model interface{}) (string, error) {
@@ -472,10 +664,22 @@ func newAPI(cache *TableCache) API {
 	}
 }
 
+// newTransactAPI returns a new API that can also run operations on the server
+func newTransactAPI(cache *TableCache, transact func(context.Context, ...ovsdb.Operation) ([]ovsdb.OperationResult, error), skipImmutable bool) API {
+	return api{
+		cache:         cache,
+		transact:      transact,
+		skipImmutable: skipImmutable,
+	}
+}
+
 // newConditionalAPI returns a new ConditionalAPI to interact with the database
-func newConditionalAPI(cache *TableCache, cond Conditional) ConditionalAPI {
+// that works like the parent API on the rows selected by the Conditional
+func newConditionalAPI(parent api, cond Conditional) ConditionalAPI {
 	return api{
-		cache: cache,
-		cond:  cond,
+		cache:         parent.cache,
+		cond:          cond,
+		transact:      parent.transact,
+		skipImmutable: parent.skipImmutable,
 	}
 }
@@ -1,6 +1,8 @@
 package client
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -1006,6 +1008,74 @@ func TestAPIUpdate(t *testing.T) {
 	}
 }
 
+func TestAPIUpdateOperation(t *testing.T) {
+	// Update used to send insert operations
+	a := newAPI(apiTestCache(t))
+	lsp := testLogicalSwitchPort{Name: "lsp0", Type: "someType"}
+	ops, err := a.Where(&lsp).Update(&lsp, &lsp.Type)
+	assert.Nil(t, err)
+	if assert.Len(t, ops, 1) {
+		assert.Equal(t, "update", ops[0].Op)
+		raw, err := json.Marshal(ops[0])
+		assert.Nil(t, err)
+		assert.Contains(t, string(raw), `"op":"update"`)
+	}
+}
+
+func TestAPIImmutableColumns(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	assert.Nil(t, json.Unmarshal(testServerSchema, &schema))
+	cache, err := newTableCache(&schema, defDB)
+	assert.Nil(t, err)
+	bridge := bridgeType{UUID: aUUID0, Name: "br0", ExternalIds: map[string]string{"foo": "bar"}}
+	renamed := bridge
+	renamed.Name = "br1"
+	nameMutation := []Mutation{{Field: &bridge.Name, Mutator: ovsdb.MutateOperationInsert, Value: "br1"}}
+	externalIdsMutation := []Mutation{{Field: &bridge.ExternalIds, Mutator: ovsdb.MutateOperationInsert, Value: map[string]string{"new": "key"}}}
+
+	// "name" is not mutable in the Bridge table
+	a := newTransactAPI(cache, nil, false)
+	var immutable *ErrImmutableColumn
+	_, err = a.Where(&bridge).Update(&bridge)
+	assert.True(t, errors.As(err, &immutable))
+	assert.Equal(t, "Bridge", immutable.Table())
+	assert.Equal(t, "name", immutable.Column())
+	assert.Equal(t, "column name of table Bridge is not mutable", err.Error())
+	_, err = a.Where(&bridge).Update(&bridge, &bridge.Name)
+	assert.True(t, errors.As(err, &immutable))
+	_, err = a.Where(&bridge).Mutate(&bridge, nameMutation)
+	assert.True(t, errors.As(err, &immutable))
+	_, err = a.Where(&bridge).UpdateDiff(&bridge, &renamed)
+	assert.True(t, errors.As(err, &immutable))
+	ops, err := a.Where(&bridge).Update(&bridge, &bridge.ExternalIds)
+	assert.Nil(t, err)
+	assert.Len(t, ops, 1)
+
+	a = newTransactAPI(cache, nil, true)
+	ops, err = a.Where(&bridge).Update(&bridge)
+	assert.Nil(t, err)
+	if assert.Len(t, ops, 1) {
+		assert.Equal(t, map[string]interface{}{"external_ids": testOvsMap(t, bridge.ExternalIds)}, ops[0].Row)
+	}
+	ops, err = a.Where(&bridge).Update(&bridge, &bridge.Name)
+	assert.Nil(t, err)
+	assert.Empty(t, ops)
+	ops, err = a.Where(&bridge).Mutate(&bridge, append(nameMutation, externalIdsMutation...))
+	assert.Nil(t, err)
+	if assert.Len(t, ops, 1) {
+		assert.Len(t, ops[0].Mutations, 1)
+	}
+	ops, err = a.Where(&bridge).Mutate(&bridge, nameMutation)
+	assert.Nil(t, err)
+	assert.Empty(t, ops)
+	ops, err = a.Where(&bridge).UpdateDiff(&bridge, &renamed)
+	assert.Nil(t, err)
+	assert.Empty(t, ops)
+	ops, err = a.WhereUnchanged(&bridge, &bridge.ExternalIds).Update(&bridge)
+	assert.Nil(t, err)
+	assert.Len(t, ops, 2)
+}
+
 func TestAPIDelete(t *testing.T) {
 	cache := apiTestCache(t)
 	lspCache := map[string]Model{
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"time"
+)
+
+// Stop is returned by a Backoff to indicate that no more attempts shall be made
+const Stop time.Duration = -1
+
+// Backoff computes the time to wait between consecutive connection attempts
+type Backoff interface {
+	// NextBackOff returns the duration to wait before the next attempt
+	// or Stop if no more attempts shall be made
+	NextBackOff() time.Duration
+	// Reset restores the Backoff to its initial state
+	Reset()
+}
+
+// ExponentialBackoff is a Backoff whose interval is multiplied by Multiplier
+// after every attempt, up to MaxInterval
+type ExponentialBackoff struct {
+	// InitialInterval is the interval returned by the first call to NextBackOff
+	InitialInterval time.Duration
+	// MaxInterval is the upper bound of the interval
+	MaxInterval time.Duration
+	// Multiplier is the factor by which the interval grows after every attempt
+	Multiplier float64
+	// MaxElapsedTime is the maximum accumulated wait after which Stop is returned.
+	// A value of 0 means the ExponentialBackoff never stops
+	MaxElapsedTime time.Duration
+
+	current time.Duration
+	elapsed time.Duration
+}
+
+// NewExponentialBackoff returns an ExponentialBackoff that starts at 500 milliseconds,
+// doubles on every attempt up to 30 seconds and never stops
+func NewExponentialBackoff() *ExponentialBackoff {
+	return &ExponentialBackoff{
+		InitialInterval: 500 * time.Millisecond,
+		MaxInterval:     30 * time.Second,
+		Multiplier:      2,
+	}
+}
+
+// NextBackOff implements the Backoff interface
+func (b *ExponentialBackoff) NextBackOff() time.Duration {
+	if b.current == 0 {
+		b.current = b.InitialInterval
+	} else {
+		b.current = time.Duration(float64(b.current) * b.Multiplier)
+	}
+	if b.MaxInterval > 0 && b.current > b.MaxInterval {
+		b.current = b.MaxInterval
+	}
+	if b.MaxElapsedTime > 0 && b.elapsed+b.current > b.MaxElapsedTime {
+		return Stop
+	}
+	b.elapsed += b.current
+	return b.current
+}
+
+// Reset implements the Backoff interface
+func (b *ExponentialBackoff) Reset() {
+	b.current = 0
+	b.elapsed = 0
+}
@@ -0,0 +1,40 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	b := &ExponentialBackoff{
+		InitialInterval: 100 * time.Millisecond,
+		MaxInterval:     400 * time.Millisecond,
+		Multiplier:      2,
+		MaxElapsedTime:  time.Second,
+	}
+	expected := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		// capped by MaxInterval, it would exceed the MaxElapsedTime
+		Stop,
+	}
+	for i, e := range expected {
+		assert.Equalf(t, e, b.NextBackOff(), "attempt %d", i)
+	}
+
+	b.Reset()
+	assert.Equal(t, 100*time.Millisecond, b.NextBackOff())
+}
+
+func TestExponentialBackoffNeverStops(t *testing.T) {
+	b := NewExponentialBackoff()
+	var last time.Duration
+	for i := 0; i < 100; i++ {
+		last = b.NextBackOff()
+		assert.NotEqual(t, Stop, last)
+	}
+	assert.Equal(t, b.MaxInterval, last)
+}
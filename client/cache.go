@@ -1,9 +1,13 @@
 package client
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"log"
 
@@ -14,16 +18,33 @@ const (
 	updateEvent = "update"
 	addEvent    = "add"
 	deleteEvent = "delete"
+	resyncEvent = "resync"
 	bufferSize  = 65536
 )
 
 // RowCache is a collections of Models hashed by UUID
+// It also maintains the indexes of the table and the references of its
+// rows to other rows, if its schema is known
 type RowCache struct {
-	cache map[string]Model
-	mutex sync.RWMutex
+	cache   map[string]Model
+	mutex   sync.RWMutex
+	table   *ovsdb.TableSchema
+	indexes []*cacheIndex
+	// references holds the UUIDs of the rows that reference each row
+	references map[rowKey]map[string]bool
+}
+
+// cacheIndex maps the values that the rows have in the columns of an index to their UUIDs
+type cacheIndex struct {
+	columns []ColumnKey
+	// client is set for ClientIndexes, whose map keys may be missing and values may be repeated
+	client bool
+	rows   map[string]map[string]bool
 }
 
 // Row returns one model from the cache by UUID
+// The model is the one stored in the cache, so it must not be modified. The
+// API and RowCopy return copies of the models that can be modified freely
 func (r *RowCache) Row(uuid string) Model {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
@@ -33,6 +54,16 @@ func (r *RowCache) Row(uuid string) Model {
 	return nil
 }
 
+// RowCopy returns a deep copy of one model from the cache by UUID
+func (r *RowCache) RowCopy(uuid string) Model {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if row, ok := r.cache[uuid]; ok {
+		return copyModel(row)
+	}
+	return nil
+}
+
 // Rows returns a list of row UUIDs as strings
 func (r *RowCache) Rows() []string {
 	r.mutex.RLock()
@@ -58,6 +89,152 @@ func newRowCache() *RowCache {
 	}
 }
 
+// newIndexedRowCache returns a RowCache that maintains the indexes of the table schema
+// and the given client indexes
+func newIndexedRowCache(table *ovsdb.TableSchema, clientIndexes []ClientIndex) *RowCache {
+	r := newRowCache()
+	r.table = table
+	r.references = make(map[rowKey]map[string]bool)
+	for _, index := range table.Indexes {
+		columns := make([]ColumnKey, 0, len(index))
+		for _, column := range index {
+			columns = append(columns, ColumnKey{Column: column})
+		}
+		r.indexes = append(r.indexes, &cacheIndex{columns: columns, rows: make(map[string]map[string]bool)})
+	}
+	for _, index := range clientIndexes {
+		r.indexes = append(r.indexes, &cacheIndex{columns: index.Columns, client: true, rows: make(map[string]map[string]bool)})
+	}
+	return r
+}
+
+// set stores a model in the cache and updates the indexes
+// The caller must hold the mutex
+func (r *RowCache) set(uuid string, model Model) {
+	r.remove(uuid)
+	r.cache[uuid] = model
+	if r.table == nil {
+		return
+	}
+	info, err := newORMInfo(r.table, model)
+	if err != nil {
+		return
+	}
+	for _, index := range r.indexes {
+		key, ok := index.key(info)
+		if !ok {
+			continue
+		}
+		if index.rows[key] == nil {
+			index.rows[key] = make(map[string]bool)
+		}
+		index.rows[key][uuid] = true
+	}
+	r.addReferences(uuid, info)
+}
+
+// remove deletes a model from the cache and the indexes
+// The caller must hold the mutex
+func (r *RowCache) remove(uuid string) {
+	model, ok := r.cache[uuid]
+	if !ok {
+		return
+	}
+	delete(r.cache, uuid)
+	if r.table == nil {
+		return
+	}
+	info, err := newORMInfo(r.table, model)
+	if err != nil {
+		return
+	}
+	for _, index := range r.indexes {
+		key, ok := index.key(info)
+		if !ok {
+			continue
+		}
+		delete(index.rows[key], uuid)
+		if len(index.rows[key]) == 0 {
+			delete(index.rows, key)
+		}
+	}
+	r.removeReferences(uuid, info)
+}
+
+// rowsByModel returns the UUIDs of the rows that have the same value as the model in any of
+// the indexes for which the model has a value: the _uuid, the indexes of the schema and, if
+// clientIndexes is set, the client indexes. The result is ordered by the priority of the index
+// the rows were found in
+// It returns false if the indexes are not maintained, in which case the rows must be searched
+func (r *RowCache) rowsByModel(model Model, clientIndexes bool) ([]string, bool, error) {
+	if r.table == nil {
+		return nil, false, nil
+	}
+	info, err := newORMInfo(r.table, model)
+	if err != nil {
+		return nil, true, err
+	}
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	var result []string
+	found := make(map[string]bool)
+	if uuid, err := info.fieldByColumn("_uuid"); err == nil && uuid != "" {
+		if _, ok := r.cache[uuid.(string)]; ok {
+			result = append(result, uuid.(string))
+			found[uuid.(string)] = true
+		}
+	}
+	for _, index := range r.indexes {
+		if index.client && !clientIndexes {
+			continue
+		}
+		key, ok := index.key(info)
+		if !ok {
+			continue
+		}
+		for uuid := range index.rows[key] {
+			if !found[uuid] {
+				result = append(result, uuid)
+				found[uuid] = true
+			}
+		}
+	}
+	return result, true, nil
+}
+
+// key returns the key of the model in the index. It returns false if the model
+// has no value for any of the columns of the index: the column has its default
+// value or, for the keys of map columns, the map does not have the key
+func (i *cacheIndex) key(info *ormInfo) (string, bool) {
+	values := make([]interface{}, 0, len(i.columns))
+	for _, column := range i.columns {
+		if !info.hasColumn(column.Column) {
+			return "", false
+		}
+		columnSchema := info.table.Column(column.Column)
+		if columnSchema == nil {
+			return "", false
+		}
+		field, err := info.fieldByColumn(column.Column)
+		if err != nil || ovsdb.IsDefaultValue(columnSchema, field) {
+			return "", false
+		}
+		if column.Key != nil {
+			value := reflect.ValueOf(field).MapIndex(reflect.ValueOf(column.Key))
+			if !value.IsValid() {
+				return "", false
+			}
+			field = value.Interface()
+		}
+		values = append(values, field)
+	}
+	key, err := json.Marshal(values)
+	if err != nil {
+		return "", false
+	}
+	return string(key), true
+}
+
 // EventHandler can handle events when the contents of the cache changes
 type EventHandler interface {
 	OnAdd(table string, model Model)
@@ -71,6 +248,7 @@ type EventHandlerFuncs struct {
 	AddFunc    func(table string, model Model)
 	UpdateFunc func(table string, old Model, new Model)
 	DeleteFunc func(table string, model Model)
+	ResyncFunc func()
 }
 
 // OnAdd calls AddFunc if it is not nil
@@ -94,6 +272,13 @@ func (e *EventHandlerFuncs) OnDelete(table string, row Model) {
 	}
 }
 
+// OnResync calls ResyncFunc if it is not nil
+func (e *EventHandlerFuncs) OnResync() {
+	if e.ResyncFunc != nil {
+		e.ResyncFunc()
+	}
+}
+
 // TableCache contains a collection of RowCaches, hashed by name,
 // and an array of EventHandlers that respond to cache updates
 type TableCache struct {
@@ -102,21 +287,107 @@ type TableCache struct {
 	eventProcessor *eventProcessor
 	orm            *orm
 	dbModel        *DBModel
+	// lastTxnID is the id of the last transaction whose changes are in the cache
+	// It is only known for monitors issued with monitor_cond_since
+	lastTxnID string
+	// unreconciled holds the tables loaded from a snapshot whose contents have not
+	// been reconciled with the server's yet
+	unreconciled map[string]bool
+	logger       *log.Logger
+	// updated is closed, and replaced, every time updates are applied to the cache
+	updated chan struct{}
+	// updateMutex serializes updates, events holds the events of the one in progress
+	updateMutex sync.Mutex
+	events      []event
 }
 
+// newTableCache creates a cache for the given DBModel. The schema may be nil
+// if it is not known yet, in which case it must be set with setSchema before
+// the cache is populated
 func newTableCache(schema *ovsdb.DatabaseSchema, dbModel *DBModel) (*TableCache, error) {
-	if schema == nil || dbModel == nil {
+	if dbModel == nil {
 		return nil, fmt.Errorf("tablecache without databasemodel cannot be populated")
 	}
-	eventProcessor := newEventProcessor(bufferSize)
+	if schema == nil {
+		schema = &ovsdb.DatabaseSchema{}
+	}
+	eventProcessor := newEventProcessor(bufferSize, EventDeliveryBlock)
 	return &TableCache{
 		cache:          make(map[string]*RowCache),
 		eventProcessor: eventProcessor,
 		orm:            newORM(schema),
 		dbModel:        dbModel,
+		logger:         log.Default(),
+		updated:        make(chan struct{}),
 	}, nil
 }
 
+// setSchema sets the schema the cache uses to translate rows into models
+// If the cache holds rows of a different version of the schema, loaded from a snapshot,
+// their indexes are rebuilt and the id of the last transaction is discarded so that
+// they are reconciled with the contents of the server
+func (t *TableCache) setSchema(schema *ovsdb.DatabaseSchema) {
+	t.cacheMutex.Lock()
+	defer t.cacheMutex.Unlock()
+	previous := t.orm.schema
+	t.orm.schema = schema
+	if previous.Name == "" || previous.Version == schema.Version {
+		return
+	}
+	t.lastTxnID = ""
+	for table, rowCache := range t.cache {
+		rebuilt := t.newRowCache(table)
+		rowCache.mutex.RLock()
+		for uuid, model := range rowCache.cache {
+			rebuilt.set(uuid, model)
+		}
+		rowCache.mutex.RUnlock()
+		t.cache[table] = rebuilt
+	}
+}
+
+// notifyUpdated wakes up the goroutines waiting for the cache to be updated
+// The caller must hold the cacheMutex
+func (t *TableCache) notifyUpdated() {
+	if t.updated == nil {
+		return
+	}
+	close(t.updated)
+	t.updated = make(chan struct{})
+}
+
+// waitFor blocks until cond is met, evaluating it every time updates are applied to the cache
+func (t *TableCache) waitFor(ctx context.Context, cond func() (bool, error)) error {
+	for {
+		t.cacheMutex.RLock()
+		updated := t.updated
+		t.cacheMutex.RUnlock()
+		if ok, err := cond(); err != nil || ok {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-updated:
+		}
+	}
+}
+
+// newRowCache returns a RowCache for a table, indexed if the table is in the schema
+func (t *TableCache) newRowCache(table string) *RowCache {
+	tableSchema := t.orm.schema.Table(table)
+	if tableSchema == nil {
+		return newRowCache()
+	}
+	return newIndexedRowCache(tableSchema, t.dbModel.Indexes(table))
+}
+
+// setLogger sets the logger used by the cache and its eventProcessor
+func (t *TableCache) setLogger(logger *log.Logger) {
+	t.logger = logger
+	t.eventProcessor.logger = logger
+}
+
 // Table returns the a Table from the cache with a given name
 func (t *TableCache) Table(name string) *RowCache {
 	t.cacheMutex.RLock()
@@ -127,6 +398,22 @@ func (t *TableCache) Table(name string) *RowCache {
 	return nil
 }
 
+// LastTransactionID returns the id of the last transaction whose changes have been applied
+// to the cache. It is only maintained by monitors issued with MonitorCondSince and it is an
+// empty string if unknown
+func (t *TableCache) LastTransactionID() string {
+	t.cacheMutex.RLock()
+	defer t.cacheMutex.RUnlock()
+	return t.lastTxnID
+}
+
+// setLastTransactionID sets the id of the last transaction whose changes are in the cache
+func (t *TableCache) setLastTransactionID(txnID string) {
+	t.cacheMutex.Lock()
+	defer t.cacheMutex.Unlock()
+	t.lastTxnID = txnID
+}
+
 // Tables returns a list of table names that are in the cache
 func (t *TableCache) Tables() []string {
 	t.cacheMutex.RLock()
@@ -147,6 +434,21 @@ func (t *TableCache) Update(context interface{}, tableUpdates ovsdb.TableUpdates
 	t.populate(tableUpdates)
 }
 
+// Update2 implements the ovsdb.Update2Handler interface
+// this populates the cache with new updates
+func (t *TableCache) Update2(context interface{}, tableUpdates ovsdb.TableUpdates2) {
+	if len(tableUpdates.Updates) == 0 {
+		return
+	}
+	t.populate2(tableUpdates)
+}
+
+// Update3 implements the ovsdb.Update3Handler interface
+// this populates the cache with new updates and records the id of their transaction
+func (t *TableCache) Update3(context interface{}, lastTxnID string, tableUpdates ovsdb.TableUpdates2) {
+	t.update3(lastTxnID, tableUpdates)
+}
+
 // Locked implements the locked method of the NotificationHandler interface
 func (t *TableCache) Locked([]interface{}) {
 }
@@ -165,8 +467,106 @@ func (t *TableCache) Disconnected() {
 
 // populate adds data to the cache and places an event on the channel
 func (t *TableCache) populate(tableUpdates ovsdb.TableUpdates) {
+	t.update(func() {
+		t.applyUpdates(tableUpdates)
+	})
+}
+
+// update runs apply, which modifies the cache and records the resulting events with
+// addEvent, with the cacheMutex held. The events are then handed to the eventProcessor,
+// once the cacheMutex is released so that handlers can read the cache if delivery blocks.
+// Updates are serialized so that their events are queued in the order they happened
+func (t *TableCache) update(apply func()) {
+	t.updateMutex.Lock()
+	defer t.updateMutex.Unlock()
+	t.cacheMutex.Lock()
+	apply()
+	t.notifyUpdated()
+	events := t.events
+	t.events = nil
+	t.cacheMutex.Unlock()
+	for _, e := range events {
+		t.eventProcessor.AddEvent(e.eventType, e.table, e.uuid, e.old, e.new)
+	}
+}
+
+// addEvent records an event to be queued once the update is complete
+// The caller must hold the cacheMutex
+func (t *TableCache) addEvent(eventType, table, uuid string, old, new Model) {
+	t.events = append(t.events, event{eventType: eventType, table: table, uuid: uuid, old: old, new: new})
+}
+
+// reconcile makes the contents of the provided tables match the rows in tableUpdates,
+// which must contain the complete contents of those tables (e.g: the reply to a monitor request).
+// Rows that are cached but not present in tableUpdates are deleted. Events are only emitted
+// for the rows that were added, deleted or that differ from the cached ones
+func (t *TableCache) reconcile(tables map[string]bool, tableUpdates ovsdb.TableUpdates) {
+	t.update(func() {
+		t.reconcileUpdates(tables, tableUpdates)
+	})
+}
+
+// populateInitial applies the initial contents of the tables of a new monitor. The tables
+// loaded from a snapshot that have not been reconciled yet are reconciled with them
+func (t *TableCache) populateInitial(tables map[string]bool, tableUpdates ovsdb.TableUpdates) {
+	t.update(func() {
+		pending := make(map[string]bool)
+		for table := range tables {
+			if t.unreconciled[table] {
+				pending[table] = true
+			}
+		}
+		t.reconcileUpdates(pending, tableUpdates)
+	})
+}
+
+// fromSnapshot returns whether the contents of the table were loaded from a snapshot
+// and have not been reconciled with the server's yet
+func (t *TableCache) fromSnapshot(table string) bool {
+	t.cacheMutex.RLock()
+	defer t.cacheMutex.RUnlock()
+	return t.unreconciled[table]
+}
+
+// setReconciled records that the contents of the tables are up to date with the server's,
+// as they are when a monitor resumes from the last transaction of a snapshot
+func (t *TableCache) setReconciled(tables map[string]bool) {
 	t.cacheMutex.Lock()
 	defer t.cacheMutex.Unlock()
+	for table := range tables {
+		delete(t.unreconciled, table)
+	}
+}
+
+// reconcileUpdates adds to tableUpdates the deletion of the cached rows that it does not
+// contain and applies them. The caller must hold the cacheMutex
+func (t *TableCache) reconcileUpdates(tables map[string]bool, tableUpdates ovsdb.TableUpdates) {
+	for table := range tables {
+		delete(t.unreconciled, table)
+		tCache, ok := t.cache[table]
+		if !ok {
+			continue
+		}
+		updates, ok := tableUpdates.Updates[table]
+		if !ok || updates.Rows == nil {
+			updates = ovsdb.TableUpdate{Rows: make(map[string]ovsdb.RowUpdate)}
+			tableUpdates.Updates[table] = updates
+		}
+		tCache.mutex.RLock()
+		for uuid := range tCache.cache {
+			if _, ok := updates.Rows[uuid]; !ok {
+				// An empty RowUpdate represents a deletion
+				updates.Rows[uuid] = ovsdb.RowUpdate{}
+			}
+		}
+		tCache.mutex.RUnlock()
+	}
+	t.applyUpdates(tableUpdates)
+}
+
+// applyUpdates applies the tableUpdates to the cache and places the resulting events on the channel
+// The caller must hold the cacheMutex
+func (t *TableCache) applyUpdates(tableUpdates ovsdb.TableUpdates) {
 	for table := range t.dbModel.Types() {
 		updates, ok := tableUpdates.Updates[table]
 		if !ok {
@@ -174,7 +574,7 @@ func (t *TableCache) populate(tableUpdates ovsdb.TableUpdates) {
 		}
 		var tCache *RowCache
 		if tCache, ok = t.cache[table]; !ok {
-			t.cache[table] = newRowCache()
+			t.cache[table] = t.newRowCache(table)
 			tCache = t.cache[table]
 		}
 		tCache.mutex.Lock()
@@ -186,27 +586,24 @@ func (t *TableCache) populate(tableUpdates ovsdb.TableUpdates) {
 				}
 				if existing, ok := tCache.cache[uuid]; ok {
 					if !reflect.DeepEqual(newModel, existing) {
-						tCache.cache[uuid] = newModel
-						oldModel, err := t.createModel(table, &row.Old, uuid)
-						if err != nil {
-							panic(err)
-						}
-						t.eventProcessor.AddEvent(updateEvent, table, oldModel, newModel)
+						tCache.set(uuid, newModel)
+						t.addEvent(updateEvent, table, uuid, existing, newModel)
 					}
 					// no diff
 					continue
 				}
-				tCache.cache[uuid] = newModel
-				t.eventProcessor.AddEvent(addEvent, table, nil, newModel)
+				tCache.set(uuid, newModel)
+				t.addEvent(addEvent, table, uuid, nil, newModel)
 				continue
 			} else {
-				oldModel, err := t.createModel(table, &row.Old, uuid)
-				if err != nil {
-					panic(err)
+				existing, ok := tCache.cache[uuid]
+				if !ok {
+					// nothing to delete
+					continue
 				}
 				// delete from cache
-				delete(tCache.cache, uuid)
-				t.eventProcessor.AddEvent(deleteEvent, table, oldModel, nil)
+				tCache.remove(uuid)
+				t.addEvent(deleteEvent, table, uuid, existing, nil)
 				continue
 			}
 		}
@@ -214,67 +611,614 @@ func (t *TableCache) populate(tableUpdates ovsdb.TableUpdates) {
 	}
 }
 
+// populate2 applies the updates of update2 notifications or monitor_cond replies
+// to the cache and places the resulting events on the channel
+func (t *TableCache) populate2(tableUpdates ovsdb.TableUpdates2) {
+	t.update(func() {
+		t.applyUpdates2(tableUpdates)
+	})
+}
+
+// update3 applies the updates of an update3 notification, or the changes in the reply
+// to a monitor_cond_since request, and records the id of the transaction they belong to
+func (t *TableCache) update3(txnID string, tableUpdates ovsdb.TableUpdates2) {
+	t.update(func() {
+		t.applyUpdates2(tableUpdates)
+		t.lastTxnID = txnID
+	})
+}
+
+// applyUpdates2 applies the updates to the cache and places the resulting events on the channel
+// The caller must hold the cacheMutex
+func (t *TableCache) applyUpdates2(tableUpdates ovsdb.TableUpdates2) {
+	for table := range t.dbModel.Types() {
+		updates, ok := tableUpdates.Updates[table]
+		if !ok {
+			continue
+		}
+		var tCache *RowCache
+		if tCache, ok = t.cache[table]; !ok {
+			t.cache[table] = t.newRowCache(table)
+			tCache = t.cache[table]
+		}
+		tCache.mutex.Lock()
+		for uuid, row := range updates.Rows {
+			existing, exists := tCache.cache[uuid]
+			switch {
+			case row.Initial != nil || row.Insert != nil:
+				newRow := row.Initial
+				if newRow == nil {
+					newRow = row.Insert
+				}
+				newModel, err := t.createModel(table, newRow, uuid)
+				if err != nil {
+					panic(err)
+				}
+				if exists {
+					if !reflect.DeepEqual(newModel, existing) {
+						tCache.set(uuid, newModel)
+						t.addEvent(updateEvent, table, uuid, existing, newModel)
+					}
+					continue
+				}
+				tCache.set(uuid, newModel)
+				t.addEvent(addEvent, table, uuid, nil, newModel)
+			case row.Modify != nil:
+				if !exists {
+					t.logger.Printf("ignoring modification of row %s of table %s that is not in the cache", uuid, table)
+					continue
+				}
+				newModel, err := t.applyModify(table, existing, row.Modify)
+				if err != nil {
+					panic(err)
+				}
+				if !reflect.DeepEqual(newModel, existing) {
+					tCache.set(uuid, newModel)
+					t.addEvent(updateEvent, table, uuid, existing, newModel)
+				}
+			case row.Delete:
+				if !exists {
+					continue
+				}
+				tCache.remove(uuid)
+				t.addEvent(deleteEvent, table, uuid, existing, nil)
+			}
+		}
+		tCache.mutex.Unlock()
+	}
+}
+
+// applyModify returns a new Model that is the result of applying the difference
+// received in the "modify" member of a RowUpdate2 to an existing Model
+// The existing Model is not modified
+func (t *TableCache) applyModify(tableName string, existing Model, diff *ovsdb.Row) (Model, error) {
+	table := t.orm.schema.Table(tableName)
+	if table == nil {
+		return nil, fmt.Errorf("table %s not found", tableName)
+	}
+	newModel, err := t.dbModel.newModel(tableName)
+	if err != nil {
+		return nil, err
+	}
+	reflect.ValueOf(newModel).Elem().Set(reflect.ValueOf(existing).Elem())
+	ormInfo, err := newORMInfo(table, newModel)
+	if err != nil {
+		return nil, err
+	}
+	for name, ovsElem := range diff.Fields {
+		column := table.Column(name)
+		if column == nil || !ormInfo.hasColumn(name) {
+			continue
+		}
+		nativeDiff, err := ovsdb.OvsToNative(column, ovsElem)
+		if err != nil {
+			return nil, fmt.Errorf("table %s, column %s: failed to extract native element: %s",
+				tableName, name, err.Error())
+		}
+		current, err := ormInfo.fieldByColumn(name)
+		if err != nil {
+			return nil, err
+		}
+		if err := ormInfo.setField(name, applyDiff(column, current, nativeDiff)); err != nil {
+			return nil, err
+		}
+	}
+	return newModel, nil
+}
+
+// applyDiff applies the difference of a column, in native format, to its current value
+// and returns the result. The current value is not modified
+// Sets with more than one element (or currently empty) get the elements in the difference
+// added if they were not present or removed if they were. Maps (with the same cardinality
+// restrictions) get the keys in the difference removed if their value is the same, or
+// added / updated otherwise. Any other value is replaced by the difference
+func applyDiff(column *ovsdb.ColumnSchema, current, diff interface{}) interface{} {
+	currentValue := reflect.ValueOf(current)
+	diffValue := reflect.ValueOf(diff)
+	switch column.Type {
+	case ovsdb.TypeSet:
+		if column.TypeObj.Max() == 1 && currentValue.Len() > 0 {
+			return diff
+		}
+		result := reflect.MakeSlice(currentValue.Type(), 0, currentValue.Len()+diffValue.Len())
+		for i := 0; i < currentValue.Len(); i++ {
+			if !sliceContains(diffValue, currentValue.Index(i)) {
+				result = reflect.Append(result, currentValue.Index(i))
+			}
+		}
+		for i := 0; i < diffValue.Len(); i++ {
+			if !sliceContains(currentValue, diffValue.Index(i)) {
+				result = reflect.Append(result, diffValue.Index(i))
+			}
+		}
+		return result.Interface()
+	case ovsdb.TypeMap:
+		if column.TypeObj.Max() == 1 && currentValue.Len() > 0 {
+			return diff
+		}
+		result := reflect.MakeMapWithSize(currentValue.Type(), currentValue.Len())
+		iter := currentValue.MapRange()
+		for iter.Next() {
+			result.SetMapIndex(iter.Key(), iter.Value())
+		}
+		iter = diffValue.MapRange()
+		for iter.Next() {
+			old := result.MapIndex(iter.Key())
+			if old.IsValid() && old.Interface() == iter.Value().Interface() {
+				result.SetMapIndex(iter.Key(), reflect.Value{})
+			} else {
+				result.SetMapIndex(iter.Key(), iter.Value())
+			}
+		}
+		return result.Interface()
+	default:
+		return diff
+	}
+}
+
+// sliceContains returns whether the slice contains the element
+func sliceContains(slice, elem reflect.Value) bool {
+	for i := 0; i < slice.Len(); i++ {
+		if slice.Index(i).Interface() == elem.Interface() {
+			return true
+		}
+	}
+	return false
+}
+
 // AddEventHandler registers the supplied EventHandler to recieve cache events
 func (t *TableCache) AddEventHandler(handler EventHandler) {
 	t.eventProcessor.AddEventHandler(handler)
 }
 
+// AddEventHandlerFor registers an EventHandler that runs in its own goroutine, with its own
+// unbounded queue, so that it does not delay the rest of handlers. It only receives the events
+// of the given tables, or of every table if none is given, that match the predicate, if not nil.
+// The predicate is a function like the ones used with WhereCache, e.g:
+// func(b *Bridge) bool { return b.ExternalIDs["owner"] == "me" }
+// which restricts the events to the table of its model. Update events are delivered if either the
+// old or the new model match the predicate, so that handlers notice the rows that stop matching
+func (t *TableCache) AddEventHandlerFor(handler EventHandler, predicate interface{}, tables ...string) error {
+	if predicate != nil {
+		table, err := api{cache: t}.getTableFromFunc(predicate)
+		if err != nil {
+			return err
+		}
+		if len(tables) > 0 && !(len(tables) == 1 && tables[0] == table) {
+			return fmt.Errorf("predicate of table %s cannot be used with events of tables %v", table, tables)
+		}
+		tables = []string{table}
+	}
+	for _, table := range tables {
+		if _, ok := t.dbModel.Types()[table]; !ok {
+			return fmt.Errorf("table %s not found in database model", table)
+		}
+	}
+	t.eventProcessor.addHandlerQueue(newHandlerQueue(handler, tables, predicate))
+	return nil
+}
+
+// RemoveEventHandler unregisters an EventHandler registered with AddEventHandler or
+// AddEventHandlerFor. The events that are pending for it are discarded
+func (t *TableCache) RemoveEventHandler(handler EventHandler) error {
+	return t.eventProcessor.RemoveEventHandler(handler)
+}
+
+// EventMetrics returns metrics about the delivery of the events of the cache
+func (t *TableCache) EventMetrics() EventMetrics {
+	return t.eventProcessor.Metrics()
+}
+
+// setEventDelivery replaces the eventProcessor with one that uses the given mode and buffer size
+// It must be called before any handler is registered
+func (t *TableCache) setEventDelivery(mode EventDeliveryMode, capacity int) {
+	eventProcessor := newEventProcessor(capacity, mode)
+	eventProcessor.logger = t.logger
+	t.eventProcessor = eventProcessor
+}
+
 // Run starts the event processing loop. It blocks until the channel is closed.
 func (t *TableCache) Run(stopCh <-chan struct{}) {
 	t.eventProcessor.Run(stopCh)
 }
 
+// runReplay replays the events of the cached rows every period until the channel is closed
+func (t *TableCache) runReplay(period time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			t.replay()
+		}
+	}
+}
+
+// replay queues an update event, with the cached model as both the old and the new one,
+// for every row in the cache, so that handlers can periodically reconcile their state
+func (t *TableCache) replay() {
+	t.update(func() {
+		for table, rowCache := range t.cache {
+			for uuid, model := range rowCache.cache {
+				t.addEvent(updateEvent, table, uuid, model, model)
+			}
+		}
+	})
+}
+
 // event encapsualtes a cache event
 type event struct {
 	eventType string
 	table     string
+	uuid      string
 	old       Model
 	new       Model
+	// seq is the sequence number assigned by the eventProcessor, zero for resync events
+	seq uint64
+}
+
+// EventDeliveryMode determines what happens to the events of the cache when the
+// handlers do not process them as fast as they are generated
+type EventDeliveryMode int
+
+const (
+	// EventDeliveryBlock makes the updates to the cache wait until there is room for their events
+	// in the buffer. No event is lost, but slow handlers delay the processing of the notifications
+	// of the server, including the replies to the inactivity probe. The time spent waiting is not
+	// considered inactivity, so the probe does not close the connection because of it, but a
+	// dead server is only detected once the handlers catch up
+	EventDeliveryBlock EventDeliveryMode = iota
+	// EventDeliveryDrop drops the events that do not fit in the buffer. Once the buffer is drained,
+	// handlers that implement ResyncHandler are notified so that they can rebuild their state
+	// from the contents of the cache
+	EventDeliveryDrop
+	// EventDeliveryCoalesce keeps at most one pending event per row, merging the events of the
+	// rows that change again before being delivered. The queue never holds more events than
+	// rows changed, so the buffer size does not apply. Intermediate states of a row may be skipped
+	EventDeliveryCoalesce
+)
+
+// ResyncHandler can be implemented by EventHandlers to be notified when events have
+// been dropped, see EventDeliveryDrop
+type ResyncHandler interface {
+	OnResync()
+}
+
+// EventMetrics contains metrics about the delivery of the events of the cache
+type EventMetrics struct {
+	// Queued is the number of events waiting to be delivered
+	Queued int
+	// Delivered is the number of events delivered to the handlers
+	Delivered uint64
+	// Dropped is the number of events dropped because the buffer was full
+	Dropped uint64
+	// Coalesced is the number of events merged with a pending event of the same row
+	Coalesced uint64
+}
+
+// rowKey identifies a row of the database
+type rowKey struct {
+	table string
+	uuid  string
 }
 
 // eventProcessor handles the queueing and processing of cache events
 type eventProcessor struct {
 	events chan event
+	mode   EventDeliveryMode
+	// coalesced holds the pending event of each row in EventDeliveryCoalesce mode
+	// order keeps the rows in the order their events were added
+	coalesceMutex sync.Mutex
+	coalesced     map[rowKey]*event
+	order         []*event
+	// inflight is the sequence number of the coalesced event being dispatched, if any
+	inflight uint64
+	// pending signals that there are coalesced events
+	pending chan struct{}
+	// resync is set when events have been dropped in EventDeliveryDrop mode
+	resync int32
 	// handlersMutex locks the handlers array when we add a handler or dispatch events
 	// we don't need a RWMutex in this case as we only have one thread reading and the write
 	// volume is very low (i.e only when AddEventHandler is called)
 	handlersMutex sync.Mutex
 	handlers      []EventHandler
+	// queues deliver events to the handlers that run in their own goroutine
+	// They have their own lock so that handlers can check whether they are synced
+	queuesMutex sync.RWMutex
+	queues      []*handlerQueue
+	logger      *log.Logger
+
+	// seq is the sequence number of the last event queued and progress the one of the
+	// last event dispatched, every event with a lower sequence number was dispatched too
+	// synced is closed, and replaced, every time there is progress in the delivery of events
+	seq       uint64
+	syncMutex sync.Mutex
+	progress  uint64
+	synced    chan struct{}
+
+	delivered uint64
+	dropped   uint64
+	merged    uint64
 }
 
-func newEventProcessor(capacity int) *eventProcessor {
+func newEventProcessor(capacity int, mode EventDeliveryMode) *eventProcessor {
 	return &eventProcessor{
-		events:   make(chan event, capacity),
-		handlers: []EventHandler{},
+		events:    make(chan event, capacity),
+		mode:      mode,
+		coalesced: make(map[rowKey]*event),
+		pending:   make(chan struct{}, 1),
+		handlers:  []EventHandler{},
+		logger:    log.Default(),
+		synced:    make(chan struct{}),
 	}
 }
 
 // AddEventHandler registers the supplied EventHandler with the eventProcessor
 // EventHandlers MUST process events quickly, for example, pushing them to a queue
 // to be processed by the client. Long Running handler functions adversely affect
-// other handlers and MAY cause loss of data if the channel buffer is full
+// other handlers and, depending on the EventDeliveryMode, the processing of updates
+// or the delivery of events
 func (e *eventProcessor) AddEventHandler(handler EventHandler) {
 	e.handlersMutex.Lock()
 	defer e.handlersMutex.Unlock()
 	e.handlers = append(e.handlers, handler)
 }
 
-// AddEvent writes an event to the channel
-func (e *eventProcessor) AddEvent(eventType string, table string, old Model, new Model) {
+// addHandlerQueue registers a handler that runs in its own goroutine, fed by the given queue
+func (e *eventProcessor) addHandlerQueue(queue *handlerQueue) {
+	e.queuesMutex.Lock()
+	defer e.queuesMutex.Unlock()
+	queue.notify = e.notifySynced
+	e.queues = append(e.queues, queue)
+	go queue.run()
+}
+
+// RemoveEventHandler unregisters an EventHandler, whether it runs in its own goroutine or not
+func (e *eventProcessor) RemoveEventHandler(handler EventHandler) error {
+	e.handlersMutex.Lock()
+	defer e.handlersMutex.Unlock()
+	for i, h := range e.handlers {
+		if reflect.DeepEqual(h, handler) {
+			e.handlers = append(e.handlers[:i], e.handlers[i+1:]...)
+			return nil
+		}
+	}
+	e.queuesMutex.Lock()
+	defer e.queuesMutex.Unlock()
+	for i, q := range e.queues {
+		if reflect.DeepEqual(q.handler, handler) {
+			e.queues = append(e.queues[:i], e.queues[i+1:]...)
+			q.stop()
+			e.notifySynced()
+			return nil
+		}
+	}
+	return fmt.Errorf("event handler not found")
+}
+
+// AddEvent queues an event according to the EventDeliveryMode
+func (e *eventProcessor) AddEvent(eventType string, table string, uuid string, old Model, new Model) {
 	// We don't need to check for error here since there
 	// is only a single writer. RPC is run in blocking mode
 	event := event{
 		eventType: eventType,
 		table:     table,
+		uuid:      uuid,
 		old:       old,
 		new:       new,
+		seq:       atomic.LoadUint64(&e.seq) + 1,
 	}
-	select {
-	case e.events <- event:
-		// noop
+	switch e.mode {
+	case EventDeliveryCoalesce:
+		e.coalesce(event)
+	case EventDeliveryDrop:
+		select {
+		case e.events <- event:
+			atomic.StoreUint64(&e.seq, event.seq)
+		default:
+			atomic.AddUint64(&e.dropped, 1)
+			atomic.StoreInt32(&e.resync, 1)
+			e.logger.Print("dropping event because event buffer is full")
+		}
+	default:
+		e.events <- event
+		atomic.StoreUint64(&e.seq, event.seq)
+	}
+}
+
+// coalesce merges the event with the pending event of the same row, if any
+func (e *eventProcessor) coalesce(ev event) {
+	e.coalesceMutex.Lock()
+	defer e.coalesceMutex.Unlock()
+	atomic.StoreUint64(&e.seq, ev.seq)
+	key := rowKey{ev.table, ev.uuid}
+	pending, ok := e.coalesced[key]
+	if !ok {
+		e.coalesced[key] = &ev
+		e.order = append(e.order, &ev)
+		select {
+		case e.pending <- struct{}{}:
+		default:
+		}
 		return
+	}
+	atomic.AddUint64(&e.merged, 1)
+	// the merged event is delivered in place of the pending one
+	ev.seq = pending.seq
+	defer func() {
+		e.setProgress(e.coalescedProgress())
+	}()
+	switch {
+	case pending.eventType == addEvent && ev.eventType == updateEvent:
+		pending.new = ev.new
+	case pending.eventType == addEvent && ev.eventType == deleteEvent:
+		// the row never existed for the handlers
+		delete(e.coalesced, key)
+	case pending.eventType == updateEvent && ev.eventType == updateEvent:
+		pending.new = ev.new
+	case pending.eventType == updateEvent && ev.eventType == deleteEvent:
+		pending.eventType = deleteEvent
+		pending.new = nil
+	case pending.eventType == deleteEvent && ev.eventType == addEvent:
+		pending.eventType = updateEvent
+		pending.new = ev.new
 	default:
-		log.Print("dropping event because event buffer is full")
+		// not a valid sequence of events, keep the latest one
+		*pending = ev
+	}
+	if pending.eventType == updateEvent && reflect.DeepEqual(pending.old, pending.new) {
+		delete(e.coalesced, key)
+	}
+}
+
+// nextCoalesced returns the oldest coalesced event
+func (e *eventProcessor) nextCoalesced() (event, bool) {
+	e.coalesceMutex.Lock()
+	defer e.coalesceMutex.Unlock()
+	if ev := e.oldestCoalesced(); ev != nil {
+		e.order = e.order[1:]
+		delete(e.coalesced, rowKey{ev.table, ev.uuid})
+		e.inflight = ev.seq
+		return *ev, true
+	}
+	return event{}, false
+}
+
+// oldestCoalesced returns the oldest coalesced event, discarding the entries of
+// the order of the events that were merged away. The caller must hold the coalesceMutex
+func (e *eventProcessor) oldestCoalesced() *event {
+	for len(e.order) > 0 {
+		ev := e.order[0]
+		if e.coalesced[rowKey{ev.table, ev.uuid}] == ev {
+			return ev
+		}
+		e.order = e.order[1:]
+	}
+	return nil
+}
+
+// coalescedDone marks the coalesced event being dispatched as delivered
+func (e *eventProcessor) coalescedDone() {
+	e.coalesceMutex.Lock()
+	e.inflight = 0
+	progress := e.coalescedProgress()
+	e.coalesceMutex.Unlock()
+	e.setProgress(progress)
+}
+
+// coalescedProgress returns the sequence number of the last event delivered, or merged away,
+// such that every event before it was too. The caller must hold the coalesceMutex
+func (e *eventProcessor) coalescedProgress() uint64 {
+	oldest := e.inflight
+	if ev := e.oldestCoalesced(); ev != nil && (oldest == 0 || ev.seq < oldest) {
+		oldest = ev.seq
+	}
+	if oldest == 0 {
+		return atomic.LoadUint64(&e.seq)
+	}
+	return oldest - 1
+}
+
+// setProgress records that every event up to seq has been dispatched
+func (e *eventProcessor) setProgress(seq uint64) {
+	e.syncMutex.Lock()
+	if seq > e.progress {
+		e.progress = seq
+	}
+	e.syncMutex.Unlock()
+	e.notifySynced()
+}
+
+// notifySynced wakes up the goroutines waiting for the delivery of events
+func (e *eventProcessor) notifySynced() {
+	e.syncMutex.Lock()
+	defer e.syncMutex.Unlock()
+	close(e.synced)
+	e.synced = make(chan struct{})
+}
+
+// lastSeq returns the sequence number of the last event queued
+func (e *eventProcessor) lastSeq() uint64 {
+	return atomic.LoadUint64(&e.seq)
+}
+
+// syncedTo returns whether every event up to seq has been delivered to the given
+// handler or, if it is nil, to every handler
+func (e *eventProcessor) syncedTo(seq uint64, handler EventHandler) bool {
+	e.syncMutex.Lock()
+	progress := e.progress
+	e.syncMutex.Unlock()
+	if progress < seq {
+		return false
+	}
+	e.queuesMutex.RLock()
+	defer e.queuesMutex.RUnlock()
+	for _, q := range e.queues {
+		if handler != nil && !reflect.DeepEqual(q.handler, handler) {
+			continue
+		}
+		if !q.syncedTo(seq) {
+			return false
+		}
+	}
+	return true
+}
+
+// waitSynced blocks until cond returns true, checking it every time there is progress
+// in the delivery of events, or the context is done
+func (e *eventProcessor) waitSynced(ctx context.Context, cond func() bool) error {
+	for {
+		e.syncMutex.Lock()
+		synced := e.synced
+		e.syncMutex.Unlock()
+		if cond() {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-synced:
+		}
+	}
+}
+
+// Metrics returns the metrics of the delivery of events
+func (e *eventProcessor) Metrics() EventMetrics {
+	e.coalesceMutex.Lock()
+	queued := len(e.events) + len(e.coalesced)
+	e.coalesceMutex.Unlock()
+	e.queuesMutex.RLock()
+	for _, q := range e.queues {
+		queued += q.len()
+	}
+	e.queuesMutex.RUnlock()
+	return EventMetrics{
+		Queued:    queued,
+		Delivered: atomic.LoadUint64(&e.delivered),
+		Dropped:   atomic.LoadUint64(&e.dropped),
+		Coalesced: atomic.LoadUint64(&e.merged),
 	}
 }
 
@@ -286,20 +1230,199 @@ func (e *eventProcessor) Run(stopCh <-chan struct{}) {
 	for {
 		select {
 		case <-stopCh:
+			e.queuesMutex.RLock()
+			for _, q := range e.queues {
+				q.stop()
+			}
+			e.queuesMutex.RUnlock()
 			return
 		case event := <-e.events:
-			e.handlersMutex.Lock()
-			for _, handler := range e.handlers {
-				switch event.eventType {
-				case addEvent:
-					handler.OnAdd(event.table, event.new)
-				case updateEvent:
-					handler.OnUpdate(event.table, event.old, event.new)
-				case deleteEvent:
-					handler.OnDelete(event.table, event.old)
+			e.dispatch(event)
+			e.setProgress(event.seq)
+			if len(e.events) == 0 && atomic.CompareAndSwapInt32(&e.resync, 1, 0) {
+				e.dispatchResync()
+			}
+		case <-e.pending:
+			for {
+				event, ok := e.nextCoalesced()
+				if !ok {
+					break
 				}
+				e.dispatch(event)
+				e.coalescedDone()
+			}
+		}
+	}
+}
+
+// dispatch delivers an event to every handler
+func (e *eventProcessor) dispatch(event event) {
+	e.handlersMutex.Lock()
+	defer e.handlersMutex.Unlock()
+	for _, handler := range e.handlers {
+		deliverEvent(handler, event)
+	}
+	e.queuesMutex.RLock()
+	defer e.queuesMutex.RUnlock()
+	for _, q := range e.queues {
+		if q.accepts(event) {
+			q.add(event)
+		}
+	}
+	atomic.AddUint64(&e.delivered, 1)
+}
+
+// dispatchResync notifies the handlers that implement ResyncHandler that events were dropped
+func (e *eventProcessor) dispatchResync() {
+	e.handlersMutex.Lock()
+	defer e.handlersMutex.Unlock()
+	for _, handler := range e.handlers {
+		deliverEvent(handler, event{eventType: resyncEvent})
+	}
+	e.queuesMutex.RLock()
+	defer e.queuesMutex.RUnlock()
+	for _, q := range e.queues {
+		q.add(event{eventType: resyncEvent})
+	}
+}
+
+// deliverEvent calls the method of the handler that corresponds to the event
+func deliverEvent(handler EventHandler, event event) {
+	switch event.eventType {
+	case addEvent:
+		handler.OnAdd(event.table, event.new)
+	case updateEvent:
+		handler.OnUpdate(event.table, event.old, event.new)
+	case deleteEvent:
+		handler.OnDelete(event.table, event.old)
+	case resyncEvent:
+		if h, ok := handler.(ResyncHandler); ok {
+			h.OnResync()
+		}
+	}
+}
+
+// handlerQueue delivers to an EventHandler, in its own goroutine, the events that pass its filters
+// The queue is unbounded so that a slow handler does not delay the rest
+type handlerQueue struct {
+	handler EventHandler
+	// tables the handler receives events for, all of them if empty
+	tables map[string]bool
+	// predicate is a func(*Model) bool that filters the events, if valid
+	predicate reflect.Value
+
+	// events holds the pending events, including the one being delivered
+	mutex   sync.Mutex
+	events  []event
+	pending chan struct{}
+	done    chan struct{}
+	stopped sync.Once
+	// notify is called after delivering each event
+	notify func()
+}
+
+func newHandlerQueue(handler EventHandler, tables []string, predicate interface{}) *handlerQueue {
+	q := &handlerQueue{
+		handler: handler,
+		tables:  make(map[string]bool, len(tables)),
+		pending: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		notify:  func() {},
+	}
+	for _, table := range tables {
+		q.tables[table] = true
+	}
+	if predicate != nil {
+		q.predicate = reflect.ValueOf(predicate)
+	}
+	return q
+}
+
+// accepts returns whether the event passes the filters of the queue. Update events
+// pass the predicate if either the old or the new model match it
+func (q *handlerQueue) accepts(event event) bool {
+	if len(q.tables) > 0 && !q.tables[event.table] {
+		return false
+	}
+	if !q.predicate.IsValid() {
+		return true
+	}
+	for _, model := range []Model{event.old, event.new} {
+		if model != nil && q.predicate.Call([]reflect.Value{reflect.ValueOf(model)})[0].Bool() {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *handlerQueue) add(event event) {
+	q.mutex.Lock()
+	q.events = append(q.events, event)
+	q.mutex.Unlock()
+	select {
+	case q.pending <- struct{}{}:
+	default:
+	}
+}
+
+func (q *handlerQueue) len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return len(q.events)
+}
+
+// syncedTo returns whether the queue has delivered every event up to seq it was given
+func (q *handlerQueue) syncedTo(seq uint64) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return len(q.events) == 0 || q.events[0].seq > seq
+}
+
+// next returns the oldest pending event without removing it from the queue
+func (q *handlerQueue) next() (event, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if len(q.events) == 0 {
+		return event{}, false
+	}
+	return q.events[0], true
+}
+
+// delivered removes the oldest pending event from the queue
+func (q *handlerQueue) delivered() {
+	q.mutex.Lock()
+	q.events[0] = event{}
+	q.events = q.events[1:]
+	q.mutex.Unlock()
+	q.notify()
+}
+
+// stop makes the goroutine of the queue exit, discarding the pending events
+func (q *handlerQueue) stop() {
+	q.stopped.Do(func() {
+		close(q.done)
+	})
+}
+
+func (q *handlerQueue) run() {
+	for {
+		select {
+		case <-q.done:
+			return
+		case <-q.pending:
+		}
+		for {
+			event, ok := q.next()
+			if !ok {
+				break
+			}
+			select {
+			case <-q.done:
+				return
+			default:
 			}
-			e.handlersMutex.Unlock()
+			deliverEvent(q.handler, event)
+			q.delivered()
 		}
 	}
 }
@@ -1,12 +1,15 @@
 package client
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"encoding/json"
 
 	"github.com/ovn-org/libovsdb/ovsdb"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type testModel struct {
@@ -369,7 +372,7 @@ func TestTableCache_populate(t *testing.T) {
 }
 
 func TestEventProcessor_AddEvent(t *testing.T) {
-	ep := newEventProcessor(16)
+	ep := newEventProcessor(16, EventDeliveryDrop)
 	var events []event
 	for i := 0; i < 17; i++ {
 		events = append(events, event{
@@ -383,7 +386,7 @@ func TestEventProcessor_AddEvent(t *testing.T) {
 	}
 	// overfill channel so event 16 is dropped
 	for _, e := range events {
-		ep.AddEvent(e.eventType, e.table, nil, e.new)
+		ep.AddEvent(e.eventType, e.table, e.uuid, nil, e.new)
 	}
 	// assert channel is full of events
 	assert.Equal(t, 16, len(ep.events))
@@ -397,3 +400,484 @@ func TestEventProcessor_AddEvent(t *testing.T) {
 	// assert channel is empty
 	assert.Equal(t, 0, len(ep.events))
 }
+
+func TestTableCache_reconcile(t *testing.T) {
+	db, err := NewDBModel("Open_vSwitch", map[string]Model{"Open_vSwitch": &testModel{}})
+	assert.Nil(t, err)
+	var schema ovsdb.DatabaseSchema
+	err = json.Unmarshal([]byte(`
+		 {"name": "TestDB",
+		  "tables": {
+		    "Open_vSwitch": {
+		      "columns": {
+		        "foo": {
+			  "type": "string"
+			}
+		      }
+		    }
+		 }
+	     }
+	`), &schema)
+	assert.Nil(t, err)
+	tc, err := newTableCache(&schema, db)
+	assert.Nil(t, err)
+
+	tc.populate(ovsdb.TableUpdates{
+		Updates: map[string]ovsdb.TableUpdate{
+			"Open_vSwitch": {
+				Rows: map[string]ovsdb.RowUpdate{
+					"unchanged": {New: ovsdb.Row{Fields: map[string]interface{}{"foo": "bar"}}},
+					"modified":  {New: ovsdb.Row{Fields: map[string]interface{}{"foo": "bar"}}},
+					"deleted":   {New: ovsdb.Row{Fields: map[string]interface{}{"foo": "bar"}}},
+				},
+			},
+		},
+	})
+	// drain the events generated by the initial population
+	for len(tc.eventProcessor.events) > 0 {
+		<-tc.eventProcessor.events
+	}
+
+	tc.reconcile(map[string]bool{"Open_vSwitch": true}, ovsdb.TableUpdates{
+		Updates: map[string]ovsdb.TableUpdate{
+			"Open_vSwitch": {
+				Rows: map[string]ovsdb.RowUpdate{
+					"unchanged": {New: ovsdb.Row{Fields: map[string]interface{}{"foo": "bar"}}},
+					"modified":  {New: ovsdb.Row{Fields: map[string]interface{}{"foo": "quux"}}},
+					"added":     {New: ovsdb.Row{Fields: map[string]interface{}{"foo": "baz"}}},
+				},
+			},
+		},
+	})
+
+	assert.Equal(t, &testModel{UUID: "unchanged", Foo: "bar"}, tc.cache["Open_vSwitch"].cache["unchanged"])
+	assert.Equal(t, &testModel{UUID: "modified", Foo: "quux"}, tc.cache["Open_vSwitch"].cache["modified"])
+	assert.Equal(t, &testModel{UUID: "added", Foo: "baz"}, tc.cache["Open_vSwitch"].cache["added"])
+	_, ok := tc.cache["Open_vSwitch"].cache["deleted"]
+	assert.False(t, ok)
+
+	events := map[string]event{}
+	for len(tc.eventProcessor.events) > 0 {
+		e := <-tc.eventProcessor.events
+		var uuid string
+		if e.new != nil {
+			uuid = e.new.(*testModel).UUID
+		} else {
+			uuid = e.old.(*testModel).UUID
+		}
+		events[uuid] = e
+	}
+	assert.Len(t, events, 3)
+	assert.Equal(t, addEvent, events["added"].eventType)
+	assert.Equal(t, deleteEvent, events["deleted"].eventType)
+	assert.Equal(t, updateEvent, events["modified"].eventType)
+	assert.Equal(t, &testModel{UUID: "modified", Foo: "bar"}, events["modified"].old)
+}
+
+func TestTableCache_populate2(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	err := json.Unmarshal(testServerSchema, &schema)
+	assert.Nil(t, err)
+	tc, err := newTableCache(&schema, defDB)
+	assert.Nil(t, err)
+
+	uuid := "2f77b348-9768-4866-b761-89d5177ecda0"
+	port0 := "b0a4e3a5-8a5b-4ef1-b6c8-1e2b1c9c0a00"
+	port1 := "b0a4e3a5-8a5b-4ef1-b6c8-1e2b1c9c0a01"
+	populate := func(rowUpdate string) {
+		var updates map[string]map[string]ovsdb.RowUpdate2
+		err := json.Unmarshal([]byte(`{"Bridge": {"`+uuid+`": `+rowUpdate+`}}`), &updates)
+		assert.Nil(t, err)
+		tc.populate2(getTableUpdates2FromRawUnmarshal(updates))
+	}
+
+	t.Log("Initial")
+	populate(`{"initial": {"name": "br0", "external_ids": ["map", [["foo", "bar"], ["baz", "quux"]]],
+	                       "ports": ["uuid", "` + port0 + `"]}}`)
+	initial := &bridgeType{
+		UUID:        uuid,
+		Name:        "br0",
+		ExternalIds: map[string]string{"foo": "bar", "baz": "quux"},
+		Ports:       []string{port0},
+	}
+	assert.Equal(t, initial, tc.Table("Bridge").Row(uuid))
+
+	t.Log("Modify")
+	populate(`{"modify": {"external_ids": ["map", [["foo", "bar"], ["baz", "changed"], ["new", "value"]]],
+	                      "ports": ["set", [["uuid", "` + port0 + `"], ["uuid", "` + port1 + `"]]]}}`)
+	modified := &bridgeType{
+		UUID:        uuid,
+		Name:        "br0",
+		ExternalIds: map[string]string{"baz": "changed", "new": "value"},
+		Ports:       []string{port1},
+	}
+	assert.Equal(t, modified, tc.Table("Bridge").Row(uuid))
+	// The previous model must not be modified as it may be held by event handlers
+	assert.Equal(t, map[string]string{"foo": "bar", "baz": "quux"}, initial.ExternalIds)
+
+	t.Log("Delete")
+	populate(`{"delete": null}`)
+	assert.Nil(t, tc.Table("Bridge").Row(uuid))
+
+	t.Log("Modify of a row that is not cached")
+	populate(`{"modify": {"name": "br1"}}`)
+	assert.Nil(t, tc.Table("Bridge").Row(uuid))
+}
+
+func TestApplyDiff(t *testing.T) {
+	var schema ovsdb.TableSchema
+	err := json.Unmarshal([]byte(`{
+		"columns": {
+			"scalar": {"type": "string"},
+			"optional": {"type": {"key": "string", "min": 0, "max": 1}},
+			"set": {"type": {"key": "string", "min": 0, "max": "unlimited"}},
+			"map": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}}
+		}
+	}`), &schema)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name     string
+		column   string
+		current  interface{}
+		diff     interface{}
+		expected interface{}
+	}{
+		{"scalar is replaced", "scalar", "foo", "bar", "bar"},
+		{"optional is set", "optional", []string{}, []string{"foo"}, []string{"foo"}},
+		{"optional is replaced", "optional", []string{"foo"}, []string{"bar"}, []string{"bar"}},
+		{"optional is cleared", "optional", []string{"foo"}, []string{}, []string{}},
+		{"set elements are toggled", "set", []string{"a", "b"}, []string{"b", "c"}, []string{"a", "c"}},
+		{"set is emptied", "set", []string{"a"}, []string{"a"}, []string{}},
+		{"map keys are added, updated and removed", "map",
+			map[string]string{"a": "1", "b": "2"},
+			map[string]string{"a": "1", "b": "3", "c": "4"},
+			map[string]string{"b": "3", "c": "4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := applyDiff(schema.Column(tt.column), tt.current, tt.diff)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestTableCache_update3(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	err := json.Unmarshal(testServerSchema, &schema)
+	assert.Nil(t, err)
+	tc, err := newTableCache(&schema, defDB)
+	assert.Nil(t, err)
+	assert.Equal(t, "", tc.LastTransactionID())
+
+	uuid := "2f77b348-9768-4866-b761-89d5177ecda0"
+	tc.update3("txn-1", ovsdb.TableUpdates2{
+		Updates: map[string]ovsdb.TableUpdate2{
+			"Bridge": {Rows: map[string]ovsdb.RowUpdate2{
+				uuid: {Insert: &ovsdb.Row{Fields: map[string]interface{}{"name": "br0"}}},
+			}},
+		},
+	})
+	assert.Equal(t, "txn-1", tc.LastTransactionID())
+	assert.Equal(t, &bridgeType{UUID: uuid, Name: "br0"}, tc.Table("Bridge").Row(uuid))
+}
+
+func TestTableCache_indexes(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	err := json.Unmarshal(testServerSchema, &schema)
+	require.Nil(t, err)
+	db, err := NewDBModel("Open_vSwitch", map[string]Model{"Open_vSwitch": &ovsType{}, "Bridge": &bridgeType{}})
+	require.Nil(t, err)
+	db.SetIndexes(map[string][]ClientIndex{
+		"Bridge": {
+			{Columns: []ColumnKey{{Column: "external_ids", Key: "owner"}}},
+			{Columns: []ColumnKey{{Column: "other_config"}}},
+		},
+	})
+	assert.Empty(t, db.Validate(&schema))
+	tc, err := newTableCache(&schema, db)
+	require.Nil(t, err)
+	api := newAPI(tc)
+
+	br0 := "2f77b348-9768-4866-b761-89d5177ecda0"
+	br1 := "2f77b348-9768-4866-b761-89d5177ecda1"
+	br2 := "2f77b348-9768-4866-b761-89d5177ecda2"
+	populate := func(updates string) {
+		var raw map[string]map[string]ovsdb.RowUpdate2
+		require.Nil(t, json.Unmarshal([]byte(`{"Bridge": `+updates+`}`), &raw))
+		tc.populate2(getTableUpdates2FromRawUnmarshal(raw))
+	}
+	names := func(bridges []bridgeType) []string {
+		var result []string
+		for _, b := range bridges {
+			result = append(result, b.Name)
+		}
+		return result
+	}
+
+	populate(`{"` + br0 + `": {"initial": {"name": "br0", "external_ids": ["map", [["owner", "foo"]]]}},
+	           "` + br1 + `": {"initial": {"name": "br1", "external_ids": ["map", [["owner", "foo"]]]}},
+	           "` + br2 + `": {"initial": {"name": "br2"}}}`)
+
+	t.Log("Get by schema index")
+	bridge := &bridgeType{Name: "br1"}
+	require.Nil(t, api.Get(bridge))
+	assert.Equal(t, br1, bridge.UUID)
+
+	t.Log("Get by uuid")
+	bridge = &bridgeType{UUID: br2}
+	require.Nil(t, api.Get(bridge))
+	assert.Equal(t, "br2", bridge.Name)
+
+	t.Log("Get without index values")
+	assert.Equal(t, ErrNotFound, api.Get(&bridgeType{}))
+
+	t.Log("Get by client index")
+	bridge = &bridgeType{ExternalIds: map[string]string{"owner": "foo"}}
+	require.Nil(t, api.Get(bridge))
+	assert.Contains(t, []string{br0, br1}, bridge.UUID)
+
+	t.Log("List by schema index")
+	var bridges []bridgeType
+	require.Nil(t, api.Where(&bridgeType{Name: "br0"}).List(&bridges))
+	assert.Equal(t, []string{"br0"}, names(bridges))
+
+	t.Log("List does not use client indexes, as the conditions sent to the server cannot")
+	bridges = nil
+	require.Nil(t, api.Where(&bridgeType{ExternalIds: map[string]string{"owner": "foo"}}).List(&bridges))
+	assert.Empty(t, bridges)
+
+	t.Log("Indexes follow modifications")
+	populate(`{"` + br0 + `": {"modify": {"name": "br0-renamed", "external_ids": ["map", [["owner", "bar"]]]}},
+	           "` + br1 + `": {"delete": null}}`)
+	assert.Equal(t, ErrNotFound, api.Get(&bridgeType{Name: "br0"}))
+	assert.Equal(t, ErrNotFound, api.Get(&bridgeType{Name: "br1"}))
+	bridge = &bridgeType{Name: "br0-renamed"}
+	require.Nil(t, api.Get(bridge))
+	assert.Equal(t, br0, bridge.UUID)
+	bridge = &bridgeType{ExternalIds: map[string]string{"owner": "bar"}}
+	require.Nil(t, api.Get(bridge))
+	assert.Equal(t, br0, bridge.UUID)
+	assert.Equal(t, ErrNotFound, api.Get(&bridgeType{ExternalIds: map[string]string{"owner": "foo"}}))
+}
+
+func TestEventProcessor_deliveryModes(t *testing.T) {
+	type delivered struct {
+		eventType string
+		old, new  Model
+	}
+	model := func(uuid, foo string) Model {
+		return &testModel{UUID: uuid, Foo: foo}
+	}
+	run := func(t *testing.T, ep *eventProcessor, n int, add func()) ([]delivered, int) {
+		received := make(chan delivered, 100)
+		resyncs := make(chan struct{}, 100)
+		ep.AddEventHandler(&EventHandlerFuncs{
+			AddFunc: func(table string, m Model) {
+				received <- delivered{addEvent, nil, m}
+			},
+			UpdateFunc: func(table string, old, new Model) {
+				received <- delivered{updateEvent, old, new}
+			},
+			DeleteFunc: func(table string, m Model) {
+				received <- delivered{deleteEvent, m, nil}
+			},
+			ResyncFunc: func() {
+				resyncs <- struct{}{}
+			},
+		})
+		add()
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		go ep.Run(stopCh)
+		var result []delivered
+		for len(result) < n {
+			select {
+			case d := <-received:
+				result = append(result, d)
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for events, got %v", result)
+			}
+		}
+		// give the processor a chance to deliver unexpected events
+		time.Sleep(50 * time.Millisecond)
+		assert.Len(t, received, 0)
+		return result, len(resyncs)
+	}
+
+	t.Run("block", func(t *testing.T) {
+		ep := newEventProcessor(1, EventDeliveryBlock)
+		done := make(chan struct{})
+		result, _ := run(t, ep, 3, func() {
+			go func() {
+				defer close(done)
+				for _, foo := range []string{"a", "b", "c"} {
+					ep.AddEvent(addEvent, "Open_vSwitch", foo, nil, model(foo, foo))
+				}
+			}()
+		})
+		<-done
+		assert.Equal(t, []delivered{
+			{addEvent, nil, model("a", "a")},
+			{addEvent, nil, model("b", "b")},
+			{addEvent, nil, model("c", "c")},
+		}, result)
+		assert.Equal(t, EventMetrics{Delivered: 3}, ep.Metrics())
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		ep := newEventProcessor(2, EventDeliveryDrop)
+		result, resyncs := run(t, ep, 2, func() {
+			for _, foo := range []string{"a", "b", "c"} {
+				ep.AddEvent(addEvent, "Open_vSwitch", foo, nil, model(foo, foo))
+			}
+			assert.Equal(t, EventMetrics{Queued: 2, Dropped: 1}, ep.Metrics())
+		})
+		assert.Equal(t, []delivered{
+			{addEvent, nil, model("a", "a")},
+			{addEvent, nil, model("b", "b")},
+		}, result)
+		assert.Equal(t, 1, resyncs)
+		assert.Equal(t, EventMetrics{Delivered: 2, Dropped: 1}, ep.Metrics())
+	})
+
+	t.Run("coalesce", func(t *testing.T) {
+		ep := newEventProcessor(1, EventDeliveryCoalesce)
+		result, _ := run(t, ep, 4, func() {
+			// added and updated
+			ep.AddEvent(addEvent, "Open_vSwitch", "a", nil, model("a", "1"))
+			ep.AddEvent(updateEvent, "Open_vSwitch", "a", model("a", "1"), model("a", "2"))
+			// added and deleted
+			ep.AddEvent(addEvent, "Open_vSwitch", "b", nil, model("b", "1"))
+			ep.AddEvent(deleteEvent, "Open_vSwitch", "b", model("b", "1"), nil)
+			// updated twice
+			ep.AddEvent(updateEvent, "Open_vSwitch", "c", model("c", "1"), model("c", "2"))
+			ep.AddEvent(updateEvent, "Open_vSwitch", "c", model("c", "2"), model("c", "3"))
+			// updated and deleted
+			ep.AddEvent(updateEvent, "Open_vSwitch", "d", model("d", "1"), model("d", "2"))
+			ep.AddEvent(deleteEvent, "Open_vSwitch", "d", model("d", "2"), nil)
+			// deleted and added again
+			ep.AddEvent(deleteEvent, "Open_vSwitch", "e", model("e", "1"), nil)
+			ep.AddEvent(addEvent, "Open_vSwitch", "e", nil, model("e", "2"))
+			// updated and reverted
+			ep.AddEvent(updateEvent, "Open_vSwitch", "f", model("f", "1"), model("f", "2"))
+			ep.AddEvent(updateEvent, "Open_vSwitch", "f", model("f", "2"), model("f", "1"))
+			assert.Equal(t, EventMetrics{Queued: 4, Coalesced: 6}, ep.Metrics())
+		})
+		assert.Equal(t, []delivered{
+			{addEvent, nil, model("a", "2")},
+			{updateEvent, model("c", "1"), model("c", "3")},
+			{deleteEvent, model("d", "1"), nil},
+			{updateEvent, model("e", "1"), model("e", "2")},
+		}, result)
+		assert.Equal(t, EventMetrics{Delivered: 4, Coalesced: 6}, ep.Metrics())
+	})
+}
+
+func TestTableCache_AddEventHandlerFor(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	require.Nil(t, json.Unmarshal(testServerSchema, &schema))
+	tc, err := newTableCache(&schema, defDB)
+	require.Nil(t, err)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go tc.Run(stopCh)
+
+	// A handler that is stuck must not delay the others
+	release := make(chan struct{})
+	defer close(release)
+	stuck := &EventHandlerFuncs{
+		AddFunc: func(string, Model) {
+			<-release
+		},
+	}
+	require.Nil(t, tc.AddEventHandlerFor(stuck, nil))
+
+	events := make(chan string, 10)
+	filtered := &EventHandlerFuncs{
+		AddFunc: func(table string, model Model) {
+			events <- "add " + model.(*bridgeType).Name
+		},
+		UpdateFunc: func(table string, old, new Model) {
+			events <- "update " + old.(*bridgeType).Name + " " + new.(*bridgeType).Name
+		},
+	}
+	err = tc.AddEventHandlerFor(filtered, func(b *bridgeType) bool { return b.ExternalIds["owner"] == "me" })
+	require.Nil(t, err)
+	ovsEvents := make(chan string, 10)
+	require.Nil(t, tc.AddEventHandlerFor(&EventHandlerFuncs{
+		AddFunc: func(table string, model Model) {
+			ovsEvents <- "add " + table
+		},
+	}, nil, "Open_vSwitch"))
+
+	assert.NotNil(t, tc.AddEventHandlerFor(filtered, nil, "Foo"))
+	assert.NotNil(t, tc.AddEventHandlerFor(filtered, func(b *bridgeType) bool { return true }, "Open_vSwitch"))
+	assert.NotNil(t, tc.AddEventHandlerFor(filtered, func(b string) bool { return true }))
+
+	populate := func(updates string) {
+		var raw map[string]map[string]ovsdb.RowUpdate2
+		require.Nil(t, json.Unmarshal([]byte(updates), &raw))
+		tc.populate2(getTableUpdates2FromRawUnmarshal(raw))
+	}
+	br0 := "2f77b348-9768-4866-b761-89d5177ecda0"
+	br1 := "2f77b348-9768-4866-b761-89d5177ecda1"
+	populate(`{"Bridge": {"` + br0 + `": {"initial": {"name": "br0", "external_ids": ["map", [["owner", "me"]]]}},
+	                      "` + br1 + `": {"initial": {"name": "br1"}}},
+	           "Open_vSwitch": {"2f77b348-9768-4866-b761-89d5177ecdaa": {"initial": {}}}}`)
+	assert.Equal(t, []string{"add br0"}, receiveEvents(t, events, 1))
+	assert.Equal(t, []string{"add Open_vSwitch"}, receiveEvents(t, ovsEvents, 1))
+
+	// rows that stop matching the predicate are notified
+	populate(`{"Bridge": {"` + br0 + `": {"modify": {"name": "br0-renamed", "external_ids": ["map", [["owner", "me"]]]}}}}`)
+	assert.Equal(t, []string{"update br0 br0-renamed"}, receiveEvents(t, events, 1))
+
+	require.Nil(t, tc.RemoveEventHandler(filtered))
+	assert.NotNil(t, tc.RemoveEventHandler(filtered))
+	populate(`{"Bridge": {"` + br1 + `": {"modify": {"external_ids": ["map", [["owner", "me"]]]}}}}`)
+	select {
+	case e := <-events:
+		t.Errorf("unexpected event %s", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+	// the events for the stuck handler remain queued
+	assert.GreaterOrEqual(t, tc.EventMetrics().Queued, 2)
+}
+
+func TestEventProcessor_syncedTo(t *testing.T) {
+	model := &testModel{UUID: "a", Foo: "a"}
+	ep := newEventProcessor(16, EventDeliveryCoalesce)
+	// events merged away count as delivered
+	ep.AddEvent(addEvent, "Open_vSwitch", "a", nil, model)
+	ep.AddEvent(deleteEvent, "Open_vSwitch", "a", model, nil)
+	assert.Equal(t, uint64(2), ep.lastSeq())
+	assert.True(t, ep.syncedTo(2, nil))
+	ep.AddEvent(addEvent, "Open_vSwitch", "b", nil, model)
+	ep.AddEvent(addEvent, "Open_vSwitch", "c", nil, model)
+	ep.AddEvent(deleteEvent, "Open_vSwitch", "c", model, nil)
+	assert.False(t, ep.syncedTo(3, nil))
+
+	release := make(chan struct{})
+	handler := &EventHandlerFuncs{
+		AddFunc: func(string, Model) {
+			<-release
+		},
+	}
+	ep.addHandlerQueue(newHandlerQueue(handler, nil, nil))
+	serial := &EventHandlerFuncs{}
+	ep.AddEventHandler(serial)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go ep.Run(stopCh)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.Nil(t, ep.waitSynced(ctx, func() bool {
+		return ep.syncedTo(5, serial)
+	}))
+	assert.False(t, ep.syncedTo(5, handler))
+	close(release)
+	require.Nil(t, ep.waitSynced(ctx, func() bool {
+		return ep.syncedTo(5, nil)
+	}))
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/cenkalti/rpc2"
 	"github.com/cenkalti/rpc2/jsonrpc"
@@ -18,21 +20,94 @@ import (
 // OvsdbClient is an OVSDB client
 type OvsdbClient struct {
 	rpcClient     *rpc2.Client
+	rpcMutex      sync.RWMutex
 	Schema        ovsdb.DatabaseSchema
 	handlers      []ovsdb.NotificationHandler
 	handlersMutex *sync.Mutex
 	Cache         *TableCache
+	runCache      sync.Once
 	stopCh        chan struct{}
 	api           API
+	dbModel       *DBModel
+	options       *options
+
+	// monitors holds the active monitor requests indexed by their json-value
+	// so that they can be re-issued after a reconnection
+	monitors      map[string]*monitor
+	monitorsMutex sync.Mutex
+	// connected is set once the client starts issuing the registered monitors
+	// Monitors registered before are kept until then
+	connected bool
+
+	// deferUpdates is set while the cache is being resynchronized after a reconnection.
+	// The dispatch of update notifications received meanwhile is queued in deferredUpdates
+	// and performed once the resynchronization has finished
+	deferUpdates    bool
+	deferredUpdates []func()
+
+	state      ConnectionState
+	stateMutex sync.Mutex
+
+	// serverORM decodes the rows of the _Server database and serverIndex is the highest
+	// raft index seen. They are protected by the rpcMutex and only used in leader-only mode
+	serverORM   *orm
+	serverIndex int
+
+	// locks holds whether each of the locks requested is currently held
+	locks      map[string]bool
+	locksMutex sync.Mutex
 }
 
-func newOvsdbClient() *OvsdbClient {
-	// Cache initialization is delayed because we first need to obtain the schema
+// monitor is a monitor request that has been issued to the server
+// Monitors registered before the first connection are issued once it is
+// established. Until then, their requests may be unknown as they depend on
+// the schema. In that case, they are built from all or tables
+type monitor struct {
+	// method is either monitor, monitor_cond or monitor_cond_since
+	method      string
+	jsonContext interface{}
+	requests    map[string]ovsdb.MonitorRequest
+	// all is set for monitors registered with MonitorAll
+	all bool
+	// tables are the TableMonitors of conditional monitors
+	tables []TableMonitor
+	// loaded is set once the initial contents of the monitor are in the cache
+	// synced is the sequence number of the last event that resulted from them
+	loaded bool
+	synced uint64
+}
+
+// NewOvsdbClient creates a new client for the database described by the DBModel.
+// The client is configured with the given options, at least one endpoint must be
+// provided with WithEndpoint or WithConfig. The client is not connected until Connect
+// is called, which allows to register handlers and monitors beforehand
+func NewOvsdbClient(database *DBModel, opts ...Option) (*OvsdbClient, error) {
+	options, err := newOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(options.endpoints) == 0 {
+		return nil, fmt.Errorf("at least one endpoint must be provided")
+	}
+	// The schema of the cache is set once the client connects
+	cache, err := newTableCache(nil, database)
+	if err != nil {
+		return nil, err
+	}
+	cache.setLogger(options.logger)
+	cache.setEventDelivery(options.eventDelivery, options.eventBufferSize)
 	ovs := &OvsdbClient{
 		handlersMutex: &sync.Mutex{},
+		Cache:         cache,
 		stopCh:        make(chan struct{}),
+		dbModel:       database,
+		options:       options,
+		monitors:      make(map[string]*monitor),
+		locks:         make(map[string]bool),
 	}
-	return ovs
+	ovs.Register(ovs.Cache)
+	ovs.api = newTransactAPI(ovs.Cache, ovs.Transact, options.skipImmutableColumns)
+	return ovs, nil
 }
 
 // Constants defined for libovsdb
@@ -46,77 +121,218 @@ const (
 
 // Connect to ovn, using endpoint in format ovsdb Connection Methods
 // If address is empty, use default address for specified protocol
-func Connect(endpoints string, database *DBModel, tlsConfig *tls.Config) (*OvsdbClient, error) {
-	var c net.Conn
-	var err error
-	var u *url.URL
+// Endpoints are tried in order until one of them accepts the connection
+// The context bounds the dialing and the initial handshake with the server
+// It is a shorthand for NewOvsdbClient followed by (*OvsdbClient).Connect
+func Connect(ctx context.Context, endpoints string, database *DBModel, tlsConfig *tls.Config, opts ...Option) (*OvsdbClient, error) {
+	opts = append([]Option{WithEndpoint(endpoints), WithTLSConfig(tlsConfig)}, opts...)
+	ovs, err := NewOvsdbClient(database, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := ovs.Connect(ctx); err != nil {
+		return nil, err
+	}
+	return ovs, nil
+}
+
+// Connect establishes the connection to the server and issues the monitors
+// registered so far, populating the cache with their initial contents
+// The context bounds the dialing, the handshake and the initial monitor requests
+// A client can only be connected once, if the connection is lost it is either
+// re-established automatically (see WithReconnect) or the client must be discarded
+func (ovs *OvsdbClient) Connect(ctx context.Context) error {
+	ovs.monitorsMutex.Lock()
+	connected := ovs.connected
+	ovs.monitorsMutex.Unlock()
+	if connected {
+		return fmt.Errorf("client is already connected")
+	}
+	select {
+	case <-ovs.stopCh:
+		return fmt.Errorf("client has been disconnected")
+	default:
+	}
+
+	ovs.setState(StateConnecting)
+	schema, err := ovs.connect(ctx)
+	if err != nil {
+		ovs.setState(StateDisconnected)
+		return err
+	}
+	ovs.Cache.setSchema(schema)
+	ovs.setConnected(true)
+	// The events of the initial contents are delivered while they are received
+	ovs.startCache()
+	if err := ovs.resync(ctx); err != nil {
+		ovs.setConnected(false)
+		ovs.rpc().Close()
+		ovs.setState(StateDisconnected)
+		return err
+	}
+
+	ovs.setState(StateConnected)
+	go ovs.handleDisconnectNotification(ovs.rpc())
+	return nil
+}
 
-	for _, endpoint := range strings.Split(endpoints, ",") {
-		if u, err = url.Parse(endpoint); err != nil {
-			return nil, err
+// startCache starts processing the events of the cache, if it was not started yet
+func (ovs *OvsdbClient) startCache() {
+	ovs.runCache.Do(func() {
+		go ovs.Cache.Run(ovs.stopCh)
+		if ovs.options.cacheResync > 0 {
+			go ovs.Cache.runReplay(ovs.options.cacheResync, ovs.stopCh)
 		}
-		// u.Opaque contains the original endPoint with the leading protocol stripped
-		// off. For example: endPoint is "tcp:127.0.0.1:6640" and u.Opaque is "127.0.0.1:6640"
-		host := u.Opaque
-		if len(host) == 0 {
-			host = defaultTCPAddress
+	})
+}
+
+func (ovs *OvsdbClient) setConnected(connected bool) {
+	ovs.monitorsMutex.Lock()
+	defer ovs.monitorsMutex.Unlock()
+	ovs.connected = connected
+}
+
+// addPendingMonitor registers a monitor to be issued once the client connects
+// It returns false, without registering it, if the client is already connected
+func (ovs *OvsdbClient) addPendingMonitor(key string, m *monitor) bool {
+	ovs.monitorsMutex.Lock()
+	defer ovs.monitorsMutex.Unlock()
+	if ovs.connected {
+		return false
+	}
+	ovs.monitors[key] = m
+	return true
+}
+
+// connect dials the endpoints in order and, once one of them accepts the
+// connection, validates the schema of the database against the model.
+// In leader-only mode, endpoints are tried until one of them is the leader.
+// It returns the schema obtained from the server
+func (ovs *OvsdbClient) connect(ctx context.Context) (*ovsdb.DatabaseSchema, error) {
+	var rpcClient *rpc2.Client
+	var conn *activityConn
+	var err error
+	for _, endpoint := range ovs.options.endpoints {
+		var c net.Conn
+		if c, err = dial(ctx, endpoint, ovs.options.tlsConfig); err != nil {
+			continue
 		}
-		switch u.Scheme {
-		case UNIX:
-			path := u.Path
-			if len(path) == 0 {
-				path = defaultUnixAddress
-			}
-			c, err = net.Dial(u.Scheme, path)
-		case TCP:
-			c, err = net.Dial(u.Scheme, host)
-		case SSL:
-			c, err = tls.Dial("tcp", host, tlsConfig)
-		default:
-			err = fmt.Errorf("unknown network protocol %s", u.Scheme)
+		if ovs.options.inactivityInterval > 0 {
+			conn = newActivityConn(c)
+			c = conn
 		}
-
-		if err == nil {
-			return newRPC2Client(c, database)
+		rpcClient = ovs.newRPC2Client(c)
+		ovs.rpcMutex.Lock()
+		ovs.rpcClient = rpcClient
+		ovs.rpcMutex.Unlock()
+		if !ovs.options.leaderOnly {
+			break
+		}
+		if err = ovs.monitorServer(ctx); err == nil {
+			break
 		}
+		err = fmt.Errorf("%s: %w", endpoint, err)
+		rpcClient.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to endpoints %q: %w", strings.Join(ovs.options.endpoints, ","), err)
+	}
+
+	schema, err := ovs.handshake(ctx)
+	if err != nil {
+		rpcClient.Close()
+		return nil, err
+	}
+	ovs.rpcMutex.Lock()
+	ovs.Schema = *schema
+	ovs.rpcMutex.Unlock()
+	if conn != nil {
+		go ovs.inactivityProbe(rpcClient, conn)
 	}
+	return schema, nil
+}
 
-	return nil, fmt.Errorf("failed to connect to endpoints %q: %v", endpoints, err)
+// dial opens a connection to an endpoint in the format of ovsdb Connection Methods
+func dial(ctx context.Context, endpoint string, tlsConfig *tls.Config) (net.Conn, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	// u.Opaque contains the original endPoint with the leading protocol stripped
+	// off. For example: endPoint is "tcp:127.0.0.1:6640" and u.Opaque is "127.0.0.1:6640"
+	host := u.Opaque
+	if len(host) == 0 {
+		host = defaultTCPAddress
+	}
+	var dialer net.Dialer
+	switch u.Scheme {
+	case UNIX:
+		path := u.Path
+		if len(path) == 0 {
+			path = defaultUnixAddress
+		}
+		return dialer.DialContext(ctx, u.Scheme, path)
+	case TCP:
+		return dialer.DialContext(ctx, u.Scheme, host)
+	case SSL:
+		tlsDialer := tls.Dialer{Config: tlsConfig}
+		return tlsDialer.DialContext(ctx, "tcp", host)
+	default:
+		return nil, fmt.Errorf("unknown network protocol %s", u.Scheme)
+	}
 }
 
-func newRPC2Client(conn net.Conn, database *DBModel) (*OvsdbClient, error) {
-	ovs := newOvsdbClient()
-	ovs.rpcClient = rpc2.NewClientWithCodec(jsonrpc.NewJSONCodec(conn))
-	ovs.rpcClient.SetBlocking(true)
-	ovs.rpcClient.Handle("echo", func(_ *rpc2.Client, args []interface{}, reply *[]interface{}) error {
+func (ovs *OvsdbClient) newRPC2Client(conn net.Conn) *rpc2.Client {
+	rpcClient := rpc2.NewClientWithCodec(newCallCodec(jsonrpc.NewJSONCodec(conn)))
+	rpcClient.SetBlocking(true)
+	// notifications are handled in the read loop, which must not be seen as inactivity
+	activity, _ := conn.(*activityConn)
+	rpcClient.Handle("echo", func(_ *rpc2.Client, args []interface{}, reply *[]interface{}) error {
 		return ovs.echo(args, reply)
 	})
-	ovs.rpcClient.Handle("update", func(_ *rpc2.Client, args []interface{}, _ *[]interface{}) error {
-		return ovs.update(args)
+	rpcClient.Handle("update", func(_ *rpc2.Client, args []interface{}, _ *[]interface{}) error {
+		return activity.deliver(func() error { return ovs.update(args) })
+	})
+	rpcClient.Handle("update2", func(_ *rpc2.Client, args []interface{}, _ *[]interface{}) error {
+		return activity.deliver(func() error { return ovs.update2(args) })
+	})
+	rpcClient.Handle("update3", func(_ *rpc2.Client, args []interface{}, _ *[]interface{}) error {
+		return activity.deliver(func() error { return ovs.update3(args) })
+	})
+	rpcClient.Handle("locked", func(_ *rpc2.Client, args []interface{}, _ *[]interface{}) error {
+		return ovs.locked(args)
 	})
-	go ovs.rpcClient.Run()
-	go ovs.handleDisconnectNotification()
+	rpcClient.Handle("stolen", func(_ *rpc2.Client, args []interface{}, _ *[]interface{}) error {
+		return ovs.stolen(args)
+	})
+	go rpcClient.Run()
+	return rpcClient
+}
 
-	dbs, err := ovs.ListDbs()
+// handshake verifies that the database exists in the server and that its schema
+// is compatible with the database model. It returns the schema
+func (ovs *OvsdbClient) handshake(ctx context.Context) (*ovsdb.DatabaseSchema, error) {
+	dbs, err := ovs.ListDbs(ctx)
 	if err != nil {
-		ovs.rpcClient.Close()
 		return nil, err
 	}
 
 	found := false
 	for _, db := range dbs {
-		if db == database.Name() {
+		if db == ovs.dbModel.Name() {
 			found = true
 			break
 		}
 	}
 	if !found {
-		ovs.rpcClient.Close()
 		return nil, fmt.Errorf("target database not found")
 	}
 
-	schema, err := ovs.GetSchema(database.Name())
-	errors := database.Validate(schema)
+	schema, err := ovs.GetSchema(ctx, ovs.dbModel.Name())
+	if err != nil {
+		return nil, err
+	}
+	errors := ovs.dbModel.Validate(schema)
 	if len(errors) > 0 {
 		var combined []string
 		for _, err := range errors {
@@ -125,25 +341,76 @@ func newRPC2Client(conn net.Conn, database *DBModel) (*OvsdbClient, error) {
 		return nil, fmt.Errorf("database validation error (%d): %s", len(errors),
 			strings.Join(combined, ". "))
 	}
+	return schema, nil
+}
+
+// rpc returns the rpc client of the current connection
+func (ovs *OvsdbClient) rpc() *rpc2.Client {
+	ovs.rpcMutex.RLock()
+	defer ovs.rpcMutex.RUnlock()
+	return ovs.rpcClient
+}
+
+// schema returns the schema of the database, which is replaced on every connection
+func (ovs *OvsdbClient) schema() ovsdb.DatabaseSchema {
+	ovs.rpcMutex.RLock()
+	defer ovs.rpcMutex.RUnlock()
+	return ovs.Schema
+}
+
+// call invokes the JSON-RPC method and waits for its response or for the context to be done,
+// in which case ctx.Err() is returned and the call is abandoned, releasing its reply slot. The
+// response is decoded into a private value that is only copied to reply on success, so a
+// response arriving after the call was abandoned is discarded without touching reply
+func (ovs *OvsdbClient) call(ctx context.Context, method string, args interface{}, reply interface{}) error {
+	rpcClient := ovs.rpc()
+	if rpcClient == nil {
+		return fmt.Errorf("client is not connected")
+	}
+	return callClient(ctx, rpcClient, method, args, reply)
+}
 
-	if err == nil {
-		ovs.Schema = *schema
-		if cache, err := newTableCache(schema, database); err == nil {
-			ovs.Cache = cache
-			ovs.Register(ovs.Cache)
-			ovs.api = newAPI(ovs.Cache)
-		} else {
-			ovs.rpcClient.Close()
-			return nil, err
+// callClient works like call on a specific rpc client
+func callClient(ctx context.Context, rpcClient *rpc2.Client, method string, args interface{}, reply interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	replyValue := reflect.New(reflect.TypeOf(reply).Elem())
+	wrapped := &callArgs{args: args}
+	call := rpcClient.Go(method, wrapped, replyValue.Interface(), make(chan *rpc2.Call, 1))
+	select {
+	case <-ctx.Done():
+		if wrapped.codec != nil {
+			wrapped.codec.abandon(wrapped.seq)
 		}
-	} else {
-		ovs.rpcClient.Close()
-		return nil, err
+		return ctx.Err()
+	case <-call.Done:
+		if call.Error != nil {
+			return call.Error
+		}
+		reflect.ValueOf(reply).Elem().Set(replyValue.Elem())
+		return nil
 	}
+}
 
-	go ovs.Cache.Run(ovs.stopCh)
+// ConnectionState returns the current state of the connection to the server
+func (ovs *OvsdbClient) ConnectionState() ConnectionState {
+	ovs.stateMutex.Lock()
+	defer ovs.stateMutex.Unlock()
+	return ovs.state
+}
 
-	return ovs, nil
+func (ovs *OvsdbClient) setState(state ConnectionState) {
+	ovs.stateMutex.Lock()
+	old := ovs.state
+	ovs.state = state
+	ovs.stateMutex.Unlock()
+	if old == state {
+		return
+	}
+	for _, handler := range ovs.options.stateHandlers {
+		handler(old, state)
+	}
 }
 
 // Register registers the supplied NotificationHandler to recieve OVSDB Notifications
@@ -192,8 +459,6 @@ func (ovs *OvsdbClient) update(params []interface{}) error {
 	if len(params) < 2 {
 		return fmt.Errorf("invalid update message")
 	}
-	// Ignore params[0] as we dont use the <json-value> currently for comparison
-
 	raw, ok := params[1].(map[string]interface{})
 	if !ok {
 		return fmt.Errorf("invalid update message")
@@ -209,52 +474,140 @@ func (ovs *OvsdbClient) update(params []interface{}) error {
 		return err
 	}
 
-	// Update the local DB cache with the tableUpdates
 	tableUpdates := getTableUpdatesFromRawUnmarshal(rowUpdates)
-	ovs.handlersMutex.Lock()
-	defer ovs.handlersMutex.Unlock()
-	for _, handler := range ovs.handlers {
-		handler.Update(params[0], tableUpdates)
+	if params[0] == serverMonitorID {
+		ovs.handleServerUpdate(tableUpdates)
+		return nil
 	}
 
+	// Update the local DB cache with the tableUpdates
+	ovs.dispatch(func() {
+		for _, handler := range ovs.handlers {
+			handler.Update(params[0], tableUpdates)
+		}
+	})
 	return nil
 }
 
+// ovsdb-server(7) : Update2 Notification Section 4.1.14
+// Processing "params": [<json-value>, <table-updates2>]
+func (ovs *OvsdbClient) update2(params []interface{}) error {
+	if len(params) < 2 {
+		return fmt.Errorf("invalid update2 message")
+	}
+	raw, ok := params[1].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid update2 message")
+	}
+	var rowUpdates map[string]map[string]ovsdb.RowUpdate2
+
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(b, &rowUpdates)
+	if err != nil {
+		return err
+	}
+
+	tableUpdates := getTableUpdates2FromRawUnmarshal(rowUpdates)
+	ovs.dispatch(func() {
+		for _, handler := range ovs.handlers {
+			if h, ok := handler.(ovsdb.Update2Handler); ok {
+				h.Update2(params[0], tableUpdates)
+			}
+		}
+	})
+	return nil
+}
+
+// ovsdb-server(7) : Update3 Notification Section 4.1.16
+// Processing "params": [<json-value>, <last-txn-id>, <table-updates2>]
+// Handlers that do not implement ovsdb.Update3Handler receive an Update2 notification
+func (ovs *OvsdbClient) update3(params []interface{}) error {
+	if len(params) < 3 {
+		return fmt.Errorf("invalid update3 message")
+	}
+	lastTxnID, ok := params[1].(string)
+	if !ok {
+		return fmt.Errorf("invalid update3 message")
+	}
+	raw, ok := params[2].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid update3 message")
+	}
+	var rowUpdates map[string]map[string]ovsdb.RowUpdate2
+
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(b, &rowUpdates)
+	if err != nil {
+		return err
+	}
+
+	tableUpdates := getTableUpdates2FromRawUnmarshal(rowUpdates)
+	ovs.dispatch(func() {
+		for _, handler := range ovs.handlers {
+			if h, ok := handler.(ovsdb.Update3Handler); ok {
+				h.Update3(params[0], lastTxnID, tableUpdates)
+			} else if h, ok := handler.(ovsdb.Update2Handler); ok {
+				h.Update2(params[0], tableUpdates)
+			}
+		}
+	})
+	return nil
+}
+
+// dispatch runs the function that delivers a notification to the handlers
+// with the handlersMutex held. While the cache is being resynchronized, it
+// is queued instead and run afterwards
+func (ovs *OvsdbClient) dispatch(deliver func()) {
+	ovs.handlersMutex.Lock()
+	defer ovs.handlersMutex.Unlock()
+	if ovs.deferUpdates {
+		ovs.deferredUpdates = append(ovs.deferredUpdates, deliver)
+		return
+	}
+	deliver()
+}
+
 // GetSchema returns the schema in use for the provided database name
 // RFC 7047 : get_schema
-func (ovs OvsdbClient) GetSchema(dbName string) (*ovsdb.DatabaseSchema, error) {
+func (ovs *OvsdbClient) GetSchema(ctx context.Context, dbName string) (*ovsdb.DatabaseSchema, error) {
 	args := ovsdb.NewGetSchemaArgs(dbName)
 	var reply ovsdb.DatabaseSchema
-	err := ovs.rpcClient.Call("get_schema", args, &reply)
+	err := ovs.call(ctx, "get_schema", args, &reply)
 	if err != nil {
 		return nil, err
 	}
-	ovs.Schema = reply
 	return &reply, err
 }
 
 // ListDbs returns the list of databases on the server
 // RFC 7047 : list_dbs
-func (ovs OvsdbClient) ListDbs() ([]string, error) {
+func (ovs *OvsdbClient) ListDbs(ctx context.Context) ([]string, error) {
 	var dbs []string
-	err := ovs.rpcClient.Call("list_dbs", nil, &dbs)
+	err := ovs.call(ctx, "list_dbs", nil, &dbs)
 	if err != nil {
-		return nil, fmt.Errorf("listdbs failure - %v", err)
+		return nil, fmt.Errorf("listdbs failure - %w", err)
 	}
 	return dbs, err
 }
 
 // Transact performs the provided Operation's on the database
 // RFC 7047 : transact
-func (ovs OvsdbClient) Transact(operation ...ovsdb.Operation) ([]ovsdb.OperationResult, error) {
+func (ovs *OvsdbClient) Transact(ctx context.Context, operation ...ovsdb.Operation) ([]ovsdb.OperationResult, error) {
 	var reply []ovsdb.OperationResult
 
-	if ok := ovs.Schema.ValidateOperations(operation...); !ok {
-		return nil, fmt.Errorf("validation failed for the operation")
+	schema := ovs.schema()
+	if err := schema.CheckOperations(operation...); err != nil {
+		return nil, fmt.Errorf("validation failed for the operation: %w", err)
 	}
 
-	args := ovsdb.NewTransactArgs(ovs.Schema.Name, operation...)
-	err := ovs.rpcClient.Call("transact", args, &reply)
+	args := ovsdb.NewTransactArgs(schema.Name, operation...)
+	err := ovs.call(ctx, "transact", args, &reply)
 	if err != nil {
 		return nil, err
 	}
@@ -262,9 +615,21 @@ func (ovs OvsdbClient) Transact(operation ...ovsdb.Operation) ([]ovsdb.Operation
 }
 
 // MonitorAll is a convenience method to monitor every table/column
-func (ovs OvsdbClient) MonitorAll(jsonContext interface{}) error {
+func (ovs *OvsdbClient) MonitorAll(ctx context.Context, jsonContext interface{}) error {
+	key, err := monitorKey(jsonContext)
+	if err != nil {
+		return err
+	}
+	if ovs.addPendingMonitor(key, &monitor{method: monitorRPC, jsonContext: jsonContext, all: true}) {
+		return nil
+	}
+	return ovs.Monitor(ctx, jsonContext, ovs.allMonitorRequests())
+}
+
+// allMonitorRequests returns the requests to monitor every column of every table in the schema
+func (ovs *OvsdbClient) allMonitorRequests() map[string]ovsdb.MonitorRequest {
 	requests := make(map[string]ovsdb.MonitorRequest)
-	for table, tableSchema := range ovs.Schema.Tables {
+	for table, tableSchema := range ovs.schema().Tables {
 		var columns []string
 		for column := range tableSchema.Columns {
 			columns = append(columns, column)
@@ -274,44 +639,107 @@ func (ovs OvsdbClient) MonitorAll(jsonContext interface{}) error {
 			Select:  ovsdb.NewDefaultMonitorSelect(),
 		}
 	}
-	return ovs.Monitor(jsonContext, requests)
+	return requests
+}
+
+// monitorRequests returns the requests of a monitor, building them if
+// it was registered before the schema was known
+func (ovs *OvsdbClient) monitorRequests(m *monitor) (map[string]ovsdb.MonitorRequest, error) {
+	if m.requests != nil {
+		return m.requests, nil
+	}
+	if m.all {
+		return ovs.allMonitorRequests(), nil
+	}
+	return ovs.newMonitorRequests(m.tables)
 }
 
 // MonitorCancel will request cancel a previously issued monitor request
 // RFC 7047 : monitor_cancel
-func (ovs OvsdbClient) MonitorCancel(jsonContext interface{}) error {
+func (ovs *OvsdbClient) MonitorCancel(ctx context.Context, jsonContext interface{}) error {
 	var reply ovsdb.OperationResult
 
+	key, err := monitorKey(jsonContext)
+	if err != nil {
+		return err
+	}
+
 	args := ovsdb.NewMonitorCancelArgs(jsonContext)
 
-	err := ovs.rpcClient.Call("monitor_cancel", args, &reply)
+	err = ovs.call(ctx, "monitor_cancel", args, &reply)
 	if err != nil {
 		return err
 	}
 	if reply.Error != "" {
 		return fmt.Errorf("error while executing transaction: %s", reply.Error)
 	}
+	ovs.monitorsMutex.Lock()
+	defer ovs.monitorsMutex.Unlock()
+	delete(ovs.monitors, key)
 	return nil
 }
 
 // Monitor will provide updates for a given table/column
 // and populate the cache with them. Subsequent updates will be processed
 // by the Update Notifications
+// If the client is not connected yet, the monitor is issued by Connect
 // RFC 7047 : monitor
-func (ovs OvsdbClient) Monitor(jsonContext interface{}, requests map[string]ovsdb.MonitorRequest) error {
-	var reply ovsdb.TableUpdates
+func (ovs *OvsdbClient) Monitor(ctx context.Context, jsonContext interface{}, requests map[string]ovsdb.MonitorRequest) error {
+	key, err := monitorKey(jsonContext)
+	if err != nil {
+		return err
+	}
+	if ovs.addPendingMonitor(key, &monitor{method: monitorRPC, jsonContext: jsonContext, requests: requests}) {
+		return nil
+	}
+	reply, err := ovs.monitor(ctx, jsonContext, requests)
+	if err != nil {
+		return err
+	}
+	ovs.monitorsMutex.Lock()
+	ovs.monitors[key] = &monitor{
+		method:      monitorRPC,
+		jsonContext: jsonContext,
+		requests:    requests,
+	}
+	ovs.monitorsMutex.Unlock()
+	ovs.Cache.populateInitial(monitoredTables(requests), reply)
+	ovs.setLoaded(key)
+	return nil
+}
 
-	args := ovsdb.NewMonitorArgs(ovs.Schema.Name, jsonContext, requests)
+// monitor sends a monitor request and returns the initial contents of the monitored tables
+func (ovs *OvsdbClient) monitor(ctx context.Context, jsonContext interface{}, requests map[string]ovsdb.MonitorRequest) (ovsdb.TableUpdates, error) {
+	args := ovsdb.NewMonitorArgs(ovs.schema().Name, jsonContext, requests)
 
 	// This totally sucks. Refer to golang JSON issue #6213
 	var response map[string]map[string]ovsdb.RowUpdate
-	err := ovs.rpcClient.Call("monitor", args, &response)
-	reply = getTableUpdatesFromRawUnmarshal(response)
+	err := ovs.call(ctx, monitorRPC, args, &response)
 	if err != nil {
-		return err
+		return ovsdb.TableUpdates{}, err
 	}
-	ovs.Cache.populate(reply)
-	return nil
+	return getTableUpdatesFromRawUnmarshal(response), nil
+}
+
+// monitorKey returns the key used to index a monitor based on its json-value, which
+// cannot be the one of the monitor of the _Server database
+func monitorKey(jsonContext interface{}) (string, error) {
+	if value, ok := jsonContext.(string); ok && value == serverMonitorID {
+		return "", fmt.Errorf("invalid monitor json-value: %q is reserved", serverMonitorID)
+	}
+	b, err := json.Marshal(jsonContext)
+	if err != nil {
+		return "", fmt.Errorf("invalid monitor json-value: %v", err)
+	}
+	return string(b), nil
+}
+
+func getTableUpdates2FromRawUnmarshal(raw map[string]map[string]ovsdb.RowUpdate2) ovsdb.TableUpdates2 {
+	tableUpdates := ovsdb.TableUpdates2{Updates: make(map[string]ovsdb.TableUpdate2)}
+	for table, update := range raw {
+		tableUpdates.Updates[table] = ovsdb.TableUpdate2{Rows: update}
+	}
+	return tableUpdates
 }
 
 func getTableUpdatesFromRawUnmarshal(raw map[string]map[string]ovsdb.RowUpdate) ovsdb.TableUpdates {
@@ -332,16 +760,165 @@ func (ovs *OvsdbClient) clearConnection() {
 	}
 }
 
-func (ovs *OvsdbClient) handleDisconnectNotification() {
-	disconnected := ovs.rpcClient.DisconnectNotify()
-	<-disconnected
+func (ovs *OvsdbClient) handleDisconnectNotification(rpcClient *rpc2.Client) {
+	<-rpcClient.DisconnectNotify()
+	select {
+	case <-ovs.stopCh:
+		// the connection was closed by Disconnect()
+	default:
+		ovs.releaseLocks()
+		if ovs.options.reconnect {
+			ovs.setState(StateReconnecting)
+			err := ovs.reconnect()
+			if err == nil {
+				ovs.setState(StateConnected)
+				go ovs.handleDisconnectNotification(ovs.rpc())
+				return
+			}
+			ovs.options.logger.Printf("reconnection failed: %v", err)
+		}
+	}
+	ovs.setState(StateDisconnected)
 	ovs.clearConnection()
 }
 
+// reconnect re-establishes the connection to the server waiting between
+// attempts as dictated by the configured Backoff. Once connected, it
+// re-issues the active monitors and resynchronizes the cache
+func (ovs *OvsdbClient) reconnect() error {
+	// abort any pending dial or request as soon as the client is disconnected
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-ovs.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	backoff := ovs.options.backoff
+	backoff.Reset()
+	for {
+		wait := backoff.NextBackOff()
+		if wait == Stop {
+			return fmt.Errorf("giving up reconnecting to endpoints %q", strings.Join(ovs.options.endpoints, ","))
+		}
+		select {
+		case <-ovs.stopCh:
+			return fmt.Errorf("client has been disconnected")
+		case <-time.After(wait):
+		}
+
+		if _, err := ovs.connect(ctx); err != nil {
+			ovs.options.logger.Printf("reconnection attempt failed: %v", err)
+			continue
+		}
+		if err := ovs.resync(ctx); err != nil {
+			ovs.options.logger.Printf("cache resynchronization failed: %v", err)
+			ovs.rpc().Close()
+			continue
+		}
+		select {
+		case <-ovs.stopCh:
+			ovs.rpc().Close()
+			return fmt.Errorf("client has been disconnected")
+		default:
+		}
+		return nil
+	}
+}
+
+// resync re-issues every active monitor on the current connection and
+// reconciles the cache with the contents of the monitored tables.
+// Update notifications received in the meantime are dispatched afterwards
+func (ovs *OvsdbClient) resync(ctx context.Context) (err error) {
+	ovs.handlersMutex.Lock()
+	ovs.deferUpdates = true
+	ovs.handlersMutex.Unlock()
+	defer func() {
+		ovs.handlersMutex.Lock()
+		defer ovs.handlersMutex.Unlock()
+		if err == nil {
+			for _, deliver := range ovs.deferredUpdates {
+				deliver()
+			}
+		}
+		ovs.deferUpdates = false
+		ovs.deferredUpdates = nil
+	}()
+
+	ovs.monitorsMutex.Lock()
+	monitors := make([]*monitor, 0, len(ovs.monitors))
+	keys := make([]string, 0, len(ovs.monitors))
+	for key, m := range ovs.monitors {
+		if m.requests, err = ovs.monitorRequests(m); err != nil {
+			ovs.monitorsMutex.Unlock()
+			return err
+		}
+		monitors = append(monitors, m)
+		keys = append(keys, key)
+	}
+	ovs.monitorsMutex.Unlock()
+
+	tables := make(map[string]bool)
+	contents := ovsdb.TableUpdates{Updates: make(map[string]ovsdb.TableUpdate)}
+	// changes received from monitors that could resume from the last transaction id.
+	// They are applied once the contents of the rest of monitors have been reconciled
+	var changes []ovsdb.TableUpdates2
+	lastTxnID := ovs.Cache.LastTransactionID()
+	for _, m := range monitors {
+		var reply ovsdb.TableUpdates
+		switch m.method {
+		case monitorCondRPC:
+			var reply2 ovsdb.TableUpdates2
+			reply2, err = ovs.monitorCond(ctx, m.jsonContext, m.requests)
+			reply = initialTableUpdates(reply2)
+		case monitorCondSinceRPC:
+			var found bool
+			var reply2 ovsdb.TableUpdates2
+			found, lastTxnID, reply2, err = ovs.monitorCondSince(ctx, m.jsonContext, m.requests, ovs.Cache.LastTransactionID())
+			if err == nil && found {
+				changes = append(changes, reply2)
+				ovs.Cache.setReconciled(monitoredTables(m.requests))
+				continue
+			}
+			reply = initialTableUpdates(reply2)
+		default:
+			reply, err = ovs.monitor(ctx, m.jsonContext, m.requests)
+		}
+		if err != nil {
+			return err
+		}
+		for table := range m.requests {
+			tables[table] = true
+		}
+		for table, update := range reply.Updates {
+			tableContents, ok := contents.Updates[table]
+			if !ok {
+				contents.Updates[table] = update
+				continue
+			}
+			for uuid, row := range update.Rows {
+				tableContents.Rows[uuid] = row
+			}
+		}
+	}
+	ovs.Cache.reconcile(tables, contents)
+	for _, change := range changes {
+		ovs.Cache.populate2(change)
+	}
+	ovs.Cache.setLastTransactionID(lastTxnID)
+	ovs.setLoaded(keys...)
+	return ovs.relock(ctx)
+}
+
 // Disconnect will close the OVSDB connection
-func (ovs OvsdbClient) Disconnect() {
+func (ovs *OvsdbClient) Disconnect() {
 	close(ovs.stopCh)
-	ovs.rpcClient.Close()
+	if rpcClient := ovs.rpc(); rpcClient != nil {
+		rpcClient.Close()
+	}
 }
 
 // Client API interface wrapper functions
@@ -349,34 +926,69 @@ func (ovs OvsdbClient) Disconnect() {
 // client object
 
 // Ensure client implementes API
-var _ API = OvsdbClient{}
+var _ API = &OvsdbClient{}
 
 //Get implements the API interface's Get function
-func (ovs OvsdbClient) Get(model Model) error {
+func (ovs *OvsdbClient) Get(model Model) error {
 	return ovs.api.Get(model)
 }
 
 //Create implementes the API interface's Create function
-func (ovs OvsdbClient) Create(models ...Model) ([]ovsdb.Operation, error) {
+func (ovs *OvsdbClient) Create(models ...Model) ([]ovsdb.Operation, error) {
 	return ovs.api.Create(models...)
 }
 
 //List implements the API interface's List function
-func (ovs OvsdbClient) List(result interface{}) error {
+func (ovs *OvsdbClient) List(result interface{}) error {
 	return ovs.api.List(result)
 }
 
 //Where implements the API interface's Where function
-func (ovs OvsdbClient) Where(m Model, conditions ...Condition) ConditionalAPI {
+func (ovs *OvsdbClient) Where(m Model, conditions ...Condition) ConditionalAPI {
 	return ovs.api.Where(m, conditions...)
 }
 
 //WhereAll implements the API interface's WhereAll function
-func (ovs OvsdbClient) WhereAll(m Model, conditions ...Condition) ConditionalAPI {
+func (ovs *OvsdbClient) WhereAll(m Model, conditions ...Condition) ConditionalAPI {
 	return ovs.api.WhereAll(m, conditions...)
 }
 
+// WhereUnchanged implements the API interface's WhereUnchanged function
+func (ovs *OvsdbClient) WhereUnchanged(m Model, fields ...interface{}) ConditionalAPI {
+	return ovs.api.WhereUnchanged(m, fields...)
+}
+
 //WhereCache implements the API interface's WhereCache function
-func (ovs OvsdbClient) WhereCache(predicate interface{}) ConditionalAPI {
+func (ovs *OvsdbClient) WhereCache(predicate interface{}) ConditionalAPI {
 	return ovs.api.WhereCache(predicate)
 }
+
+// Upsert implements the API interface's Upsert function
+func (ovs *OvsdbClient) Upsert(model Model, fields ...interface{}) ([]ovsdb.Operation, error) {
+	return ovs.api.Upsert(model, fields...)
+}
+
+// References implements the API interface's References function
+func (ovs *OvsdbClient) References(model Model, field interface{}, result interface{}) error {
+	return ovs.api.References(model, field, result)
+}
+
+// ReferencedBy implements the API interface's ReferencedBy function
+func (ovs *OvsdbClient) ReferencedBy(model Model, result interface{}) error {
+	return ovs.api.ReferencedBy(model, result)
+}
+
+// Comment implements the API interface's Comment function
+func (ovs *OvsdbClient) Comment(comment string) ([]ovsdb.Operation, error) {
+	return ovs.api.Comment(comment)
+}
+
+// Assert implements the API interface's Assert function
+func (ovs *OvsdbClient) Assert(lock string) ([]ovsdb.Operation, error) {
+	return ovs.api.Assert(lock)
+}
+
+// Abort implements the API interface's Abort function
+func (ovs *OvsdbClient) Abort() ([]ovsdb.Operation, error) {
+	return ovs.api.Abort()
+}
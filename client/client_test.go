@@ -1,11 +1,23 @@
 package client
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/cenkalti/rpc2"
+	"github.com/cenkalti/rpc2/jsonrpc"
 	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func updateBenchmark(bridges []string, b *testing.B) {
@@ -158,3 +170,1028 @@ func TestUpdate(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+var testServerSchema = []byte(`{
+  "name": "Open_vSwitch",
+  "version": "8.2.0",
+  "tables": {
+    "Open_vSwitch": {
+      "columns": {
+        "bridges": {"type": {"key": {"type": "uuid", "refTable": "Bridge", "refType": "strong"},
+                             "min": 0, "max": "unlimited"}}
+      },
+      "maxRows": 1,
+      "isRoot": true
+    },
+    "Bridge": {
+      "columns": {
+        "name": {"type": "string", "mutable": false},
+        "other_config": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}},
+        "external_ids": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}},
+        "ports": {"type": {"key": {"type": "uuid", "refTable": "Port", "refType": "strong"},
+                           "min": 0, "max": "unlimited"}},
+        "status": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"},
+                   "ephemeral": true}
+      },
+      "indexes": [["name"]]
+    }
+  }
+}`)
+
+var testServerDatabaseSchema = []byte(`{
+  "name": "_Server",
+  "version": "1.1.0",
+  "tables": {
+    "Database": {
+      "columns": {
+        "name": {"type": "string"},
+        "model": {"type": {"key": {"type": "string", "enum": ["set", ["standalone", "clustered", "relay"]]}}},
+        "connected": {"type": "boolean"},
+        "leader": {"type": "boolean"},
+        "schema": {"type": {"key": {"type": "string"}, "min": 0, "max": 1}},
+        "cid": {"type": {"key": {"type": "uuid"}, "min": 0, "max": 1}},
+        "sid": {"type": {"key": {"type": "uuid"}, "min": 0, "max": 1}},
+        "index": {"type": {"key": {"type": "integer"}, "min": 0, "max": 1}}
+      },
+      "isRoot": true
+    }
+  }
+}`)
+
+// testOvsdbServer is a minimal in-process OVSDB server that serves the
+// testServerSchema. It is used to exercise the client without an ovsdb-server
+// If the Database table has any row, the _Server database is served too
+type testOvsdbServer struct {
+	t        *testing.T
+	listener net.Listener
+	mutex    sync.Mutex
+	// tables holds the rows of each table, in OVSDB notation, indexed by uuid
+	tables map[string]map[string]map[string]interface{}
+	// handlers overrides or extends the methods served
+	handlers map[string]interface{}
+	clients  []*rpc2.Client
+	// handshakes is the number of times the schema of the database has been requested
+	handshakes int
+}
+
+func newTestOvsdbServer(t *testing.T) *testOvsdbServer {
+	listener, err := net.Listen("unix", filepath.Join(t.TempDir(), "db.sock"))
+	require.Nil(t, err)
+	s := &testOvsdbServer{
+		t:        t,
+		listener: listener,
+		tables:   make(map[string]map[string]map[string]interface{}),
+		handlers: make(map[string]interface{}),
+	}
+	t.Cleanup(s.close)
+	return s
+}
+
+// start begins accepting connections. Handlers must be set up before calling it
+func (s *testOvsdbServer) start() {
+	go s.serve()
+}
+
+func (s *testOvsdbServer) endpoint() string {
+	return "unix:" + s.listener.Addr().String()
+}
+
+func (s *testOvsdbServer) setRow(table, uuid string, row map[string]interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.tables[table]; !ok {
+		s.tables[table] = make(map[string]map[string]interface{})
+	}
+	if row == nil {
+		delete(s.tables[table], uuid)
+		return
+	}
+	s.tables[table][uuid] = row
+}
+
+// dropConnections closes the connection to every client
+func (s *testOvsdbServer) dropConnections() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, c := range s.clients {
+		c.Close()
+	}
+	s.clients = nil
+}
+
+// notify sends a notification to every client that is still connected
+func (s *testOvsdbServer) notify(method string, params ...interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, c := range s.clients {
+		if err := c.Notify(method, params); err != nil && err != rpc2.ErrShutdown {
+			s.t.Errorf("failed to send %s notification: %v", method, err)
+		}
+	}
+}
+
+func (s *testOvsdbServer) close() {
+	s.listener.Close()
+	s.dropConnections()
+}
+
+func (s *testOvsdbServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		c := rpc2.NewClientWithCodec(jsonrpc.NewJSONCodec(conn))
+		c.SetBlocking(true)
+		handlers := map[string]interface{}{
+			"list_dbs": func(_ *rpc2.Client, _ []interface{}, reply *[]string) error {
+				*reply = []string{"Open_vSwitch"}
+				if s.serverDatabase() {
+					*reply = append(*reply, "_Server")
+				}
+				return nil
+			},
+			"get_schema": func(_ *rpc2.Client, args []interface{}, reply *json.RawMessage) error {
+				if args[0] == "_Server" && s.serverDatabase() {
+					*reply = testServerDatabaseSchema
+					return nil
+				}
+				s.mutex.Lock()
+				s.handshakes++
+				s.mutex.Unlock()
+				*reply = testServerSchema
+				return nil
+			},
+			"monitor": func(_ *rpc2.Client, args []interface{}, reply *map[string]map[string]map[string]interface{}) error {
+				*reply = s.tableContents(args[2].(map[string]interface{}))
+				return nil
+			},
+			"echo": func(_ *rpc2.Client, args []interface{}, reply *[]interface{}) error {
+				*reply = args
+				return nil
+			},
+			"monitor_cancel": func(_ *rpc2.Client, _ []interface{}, reply *map[string]interface{}) error {
+				*reply = map[string]interface{}{}
+				return nil
+			},
+		}
+		s.mutex.Lock()
+		for method, handler := range s.handlers {
+			handlers[method] = handler
+		}
+		s.clients = append(s.clients, c)
+		s.mutex.Unlock()
+		for method, handler := range handlers {
+			c.Handle(method, handler)
+		}
+		go c.Run()
+	}
+}
+
+func (s *testOvsdbServer) serverDatabase() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.tables["Database"]) > 0
+}
+
+func (s *testOvsdbServer) handshakeCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.handshakes
+}
+
+// tableContents returns the initial contents of the requested tables as sent in a monitor reply
+func (s *testOvsdbServer) tableContents(requests map[string]interface{}) map[string]map[string]map[string]interface{} {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	result := make(map[string]map[string]map[string]interface{})
+	for table := range requests {
+		if len(s.tables[table]) == 0 {
+			continue
+		}
+		result[table] = make(map[string]map[string]interface{})
+		for uuid, row := range s.tables[table] {
+			result[table][uuid] = map[string]interface{}{"new": row}
+		}
+	}
+	return result
+}
+
+func testBackoff() Backoff {
+	return &ExponentialBackoff{
+		InitialInterval: 10 * time.Millisecond,
+		MaxInterval:     50 * time.Millisecond,
+		Multiplier:      2,
+	}
+}
+
+func receiveEvents(t *testing.T, events <-chan string, n int) []string {
+	var received []string
+	timeout := time.After(5 * time.Second)
+	for len(received) < n {
+		select {
+		case e := <-events:
+			received = append(received, e)
+		case <-timeout:
+			t.Fatalf("timed out waiting for events, got %v", received)
+		}
+	}
+	return received
+}
+
+func TestReconnect(t *testing.T) {
+	const (
+		br0 = "2f77b348-9768-4866-b761-89d5177ecda0"
+		br1 = "2f77b348-9768-4866-b761-89d5177ecda1"
+		br2 = "2f77b348-9768-4866-b761-89d5177ecda2"
+	)
+	server := newTestOvsdbServer(t)
+	server.setRow("Bridge", br0, map[string]interface{}{"name": "br0"})
+	server.setRow("Bridge", br1, map[string]interface{}{"name": "br1"})
+	server.start()
+
+	states := make(chan ConnectionState, 10)
+	ovs, err := Connect(context.Background(), server.endpoint(), defDB, nil,
+		WithReconnect(testBackoff()),
+		WithConnectionStateHandler(func(_, new ConnectionState) {
+			states <- new
+		}))
+	require.Nil(t, err)
+	defer ovs.Disconnect()
+	assert.Equal(t, StateConnecting, <-states)
+	assert.Equal(t, StateConnected, <-states)
+
+	events := make(chan string, 10)
+	ovs.Cache.AddEventHandler(&EventHandlerFuncs{
+		AddFunc: func(table string, model Model) {
+			events <- "add " + model.(*bridgeType).Name
+		},
+		UpdateFunc: func(table string, old, new Model) {
+			events <- "update " + old.(*bridgeType).Name + " " + new.(*bridgeType).Name
+		},
+		DeleteFunc: func(table string, model Model) {
+			events <- "delete " + model.(*bridgeType).Name
+		},
+	})
+
+	err = ovs.MonitorAll(context.Background(), "")
+	require.Nil(t, err)
+	assert.ElementsMatch(t, []string{br0, br1}, ovs.Cache.Table("Bridge").Rows())
+
+	// Consume the events of the initial dump
+	assert.ElementsMatch(t, []string{"add br0", "add br1"}, receiveEvents(t, events, 2))
+
+	// Change the database while the client is disconnected
+	server.setRow("Bridge", br0, map[string]interface{}{"name": "br0-renamed"})
+	server.setRow("Bridge", br1, nil)
+	server.setRow("Bridge", br2, map[string]interface{}{"name": "br2"})
+	server.dropConnections()
+
+	assert.Equal(t, StateReconnecting, <-states)
+	assert.Equal(t, StateConnected, <-states)
+
+	received := receiveEvents(t, events, 3)
+	assert.ElementsMatch(t, []string{"update br0 br0-renamed", "delete br1", "add br2"}, received)
+	select {
+	case e := <-events:
+		t.Errorf("unexpected event %s", e)
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.ElementsMatch(t, []string{br0, br2}, ovs.Cache.Table("Bridge").Rows())
+}
+
+func TestReconnectDisabled(t *testing.T) {
+	server := newTestOvsdbServer(t)
+	server.start()
+
+	disconnected := make(chan bool)
+	ovs, err := Connect(context.Background(), server.endpoint(), defDB, nil,
+		WithConnectionStateHandler(func(_, new ConnectionState) {
+			if new == StateDisconnected {
+				close(disconnected)
+			}
+		}))
+	require.Nil(t, err)
+	assert.Equal(t, StateConnected, ovs.ConnectionState())
+
+	server.dropConnections()
+	select {
+	case <-disconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for disconnection")
+	}
+	assert.Equal(t, StateDisconnected, ovs.ConnectionState())
+}
+
+func TestTransactContextCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := newTestOvsdbServer(t)
+	server.handlers["transact"] = func(_ *rpc2.Client, _ []interface{}, reply *[]interface{}) error {
+		<-release
+		*reply = []interface{}{map[string]interface{}{"rows": []interface{}{}}}
+		return nil
+	}
+	server.start()
+
+	ovs, err := Connect(context.Background(), server.endpoint(), defDB, nil)
+	require.Nil(t, err)
+	defer ovs.Disconnect()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	reply, err := ovs.Transact(ctx, ovsdb.Operation{Op: "select", Table: "Bridge", Where: []ovsdb.Condition{}})
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Nil(t, reply)
+
+	// The late response is discarded and the connection remains usable
+	close(release)
+	dbs, err := ovs.ListDbs(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, []string{"Open_vSwitch"}, dbs)
+
+	// A context that is already done does not send the request
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = ovs.ListDbs(ctx)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestTransactConstraintViolation(t *testing.T) {
+	server := newTestOvsdbServer(t)
+	transacted := false
+	server.handlers["transact"] = func(_ *rpc2.Client, _ []interface{}, reply *[]interface{}) error {
+		transacted = true
+		*reply = []interface{}{map[string]interface{}{}}
+		return nil
+	}
+	server.start()
+
+	ovs, err := Connect(context.Background(), server.endpoint(), defDB, nil)
+	require.Nil(t, err)
+	defer ovs.Disconnect()
+
+	_, err = ovs.Transact(context.Background(), ovsdb.Operation{
+		Op:    "insert",
+		Table: "Bridge",
+		Row:   map[string]interface{}{"name": "br0", "ports": "not a uuid"},
+	})
+	var violation *ovsdb.ErrConstraintViolation
+	require.True(t, errors.As(err, &violation))
+	assert.Contains(t, err.Error(), "column ports")
+	assert.False(t, transacted)
+}
+
+func TestConnectContextCancel(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	server := newTestOvsdbServer(t)
+	server.handlers["list_dbs"] = func(_ *rpc2.Client, _ []interface{}, reply *[]string) error {
+		<-release
+		return nil
+	}
+	server.start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	ovs, err := Connect(ctx, server.endpoint(), defDB, nil)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Nil(t, ovs)
+}
+
+func TestMonitorReservedJSONValue(t *testing.T) {
+	ovs, err := NewOvsdbClient(defDB, WithEndpoint("unix:/tmp/db.sock"))
+	require.Nil(t, err)
+	ctx := context.Background()
+	assert.NotNil(t, ovs.Monitor(ctx, serverMonitorID, map[string]ovsdb.MonitorRequest{}))
+	assert.NotNil(t, ovs.MonitorAll(ctx, serverMonitorID))
+	assert.NotNil(t, ovs.MonitorCond(ctx, serverMonitorID))
+	assert.NotNil(t, ovs.MonitorCondSince(ctx, serverMonitorID))
+	assert.Empty(t, ovs.monitors)
+}
+
+func TestMonitorCond(t *testing.T) {
+	const (
+		br0 = "2f77b348-9768-4866-b761-89d5177ecda0"
+		br1 = "2f77b348-9768-4866-b761-89d5177ecda1"
+	)
+	var mutex sync.Mutex
+	var requests []string
+	record := func(v interface{}) {
+		b, err := json.Marshal(v)
+		require.Nil(t, err)
+		mutex.Lock()
+		defer mutex.Unlock()
+		requests = append(requests, string(b))
+	}
+	lastRequest := func() string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return requests[len(requests)-1]
+	}
+
+	server := newTestOvsdbServer(t)
+	server.handlers["monitor_cond"] = func(_ *rpc2.Client, args []interface{}, reply *map[string]interface{}) error {
+		record(args[2])
+		// the first request monitors br0 and, after the change of conditions, br1
+		uuid, row := br0, map[string]interface{}{"name": "br0"}
+		if strings.Contains(lastRequest(), "br1") {
+			uuid, row = br1, map[string]interface{}{"name": "br1"}
+		}
+		*reply = map[string]interface{}{
+			"Bridge": map[string]interface{}{uuid: map[string]interface{}{"initial": row}},
+		}
+		return nil
+	}
+	server.handlers["monitor_cond_change"] = func(_ *rpc2.Client, args []interface{}, reply *map[string]interface{}) error {
+		record(args)
+		*reply = map[string]interface{}{}
+		go server.notify("update2", args[1], map[string]interface{}{
+			"Bridge": map[string]interface{}{
+				br0: map[string]interface{}{"delete": nil},
+				br1: map[string]interface{}{"insert": map[string]interface{}{"name": "br1"}},
+			},
+		})
+		return nil
+	}
+	server.start()
+
+	ovs, err := Connect(context.Background(), server.endpoint(), defDB, nil, WithReconnect(testBackoff()))
+	require.Nil(t, err)
+	defer ovs.Disconnect()
+
+	bridge := &bridgeType{}
+	err = ovs.MonitorCond(context.Background(), "cond", TableMonitor{
+		Model:      bridge,
+		Fields:     []interface{}{&bridge.Name, &bridge.ExternalIds},
+		Conditions: []Condition{{Field: &bridge.Name, Function: ovsdb.ConditionEqual, Value: "br0"}},
+	})
+	require.Nil(t, err)
+	assert.JSONEq(t, `{"Bridge": {"columns": ["name", "external_ids"], "where": [["name", "==", "br0"]],
+		"select": {"initial": true, "insert": true, "delete": true, "modify": true}}}`, lastRequest())
+	assert.Equal(t, &bridgeType{UUID: br0, Name: "br0"}, ovs.Cache.Table("Bridge").Row(br0))
+
+	// update2 notifications are applied to the cache
+	server.notify("update2", "cond", map[string]interface{}{
+		"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"modify": map[string]interface{}{
+				"external_ids": []interface{}{"map", []interface{}{[]interface{}{"foo", "bar"}}},
+			}},
+		},
+	})
+	assert.Eventually(t, func() bool {
+		row := ovs.Cache.Table("Bridge").Row(br0)
+		return row != nil && reflect.DeepEqual(map[string]string{"foo": "bar"}, row.(*bridgeType).ExternalIds)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Changing the conditions updates the cache with the rows that now match
+	err = ovs.MonitorCondChange(context.Background(), "cond", TableMonitor{
+		Model:      bridge,
+		Conditions: []Condition{{Field: &bridge.Name, Function: ovsdb.ConditionEqual, Value: "br1"}},
+	})
+	require.Nil(t, err)
+	assert.JSONEq(t, `["cond", "cond", {"Bridge": [{"where": [["name", "==", "br1"]]}]}]`, lastRequest())
+	assert.Eventually(t, func() bool {
+		return reflect.DeepEqual([]string{br1}, ovs.Cache.Table("Bridge").Rows())
+	}, 5*time.Second, 10*time.Millisecond)
+	// the monitor remains synced
+	assert.True(t, ovs.HasSynced("cond"))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Nil(t, ovs.WaitForCacheSync(ctx))
+
+	// Only tables of the monitor can be changed
+	err = ovs.MonitorCondChange(context.Background(), "cond", TableMonitor{Model: &ovsType{}})
+	assert.NotNil(t, err)
+	err = ovs.MonitorCondChange(context.Background(), "unknown", TableMonitor{Model: bridge})
+	assert.NotNil(t, err)
+
+	// The monitor is re-issued with the new conditions after a reconnection
+	server.dropConnections()
+	assert.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(requests) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.JSONEq(t, `{"Bridge": {"columns": ["name", "external_ids"], "where": [["name", "==", "br1"]],
+		"select": {"initial": true, "insert": true, "delete": true, "modify": true}}}`, lastRequest())
+	assert.Eventually(t, func() bool {
+		return ovs.ConnectionState() == StateConnected
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{br1}, ovs.Cache.Table("Bridge").Rows())
+}
+
+func TestMonitorCondSince(t *testing.T) {
+	const (
+		br0 = "2f77b348-9768-4866-b761-89d5177ecda0"
+		br1 = "2f77b348-9768-4866-b761-89d5177ecda1"
+		br2 = "2f77b348-9768-4866-b761-89d5177ecda2"
+	)
+	row := func(name string) map[string]interface{} {
+		return map[string]interface{}{"name": name}
+	}
+	// replies to the consecutive monitor_cond_since requests
+	replies := []interface{}{
+		[]interface{}{false, "txn-1", map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"initial": row("br0")},
+			br1: map[string]interface{}{"initial": row("br1")},
+		}}},
+		// the changes since txn-2 can be provided
+		[]interface{}{true, "txn-3", map[string]interface{}{"Bridge": map[string]interface{}{
+			br1: map[string]interface{}{"delete": nil},
+		}}},
+		// txn-3 is too old, the whole contents are sent again
+		[]interface{}{false, "txn-9", map[string]interface{}{"Bridge": map[string]interface{}{
+			br2: map[string]interface{}{"initial": row("br2")},
+		}}},
+	}
+	var mutex sync.Mutex
+	var txnIDs []string
+	server := newTestOvsdbServer(t)
+	server.handlers["monitor_cond_since"] = func(_ *rpc2.Client, args []interface{}, reply *interface{}) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		txnIDs = append(txnIDs, args[3].(string))
+		*reply = replies[len(txnIDs)-1]
+		return nil
+	}
+	server.start()
+
+	ovs, err := Connect(context.Background(), server.endpoint(), defDB, nil, WithReconnect(testBackoff()))
+	require.Nil(t, err)
+	defer ovs.Disconnect()
+
+	events := make(chan string, 10)
+	ovs.Cache.AddEventHandler(&EventHandlerFuncs{
+		AddFunc: func(table string, model Model) {
+			events <- "add " + model.(*bridgeType).Name
+		},
+		UpdateFunc: func(table string, old, new Model) {
+			events <- "update " + new.(*bridgeType).Name
+		},
+		DeleteFunc: func(table string, model Model) {
+			events <- "delete " + model.(*bridgeType).Name
+		},
+	})
+
+	err = ovs.MonitorCondSince(context.Background(), "since", TableMonitor{Model: &bridgeType{}})
+	require.Nil(t, err)
+	assert.Equal(t, []string{zeroTransactionID}, txnIDs)
+	assert.Equal(t, "txn-1", ovs.Cache.LastTransactionID())
+	assert.ElementsMatch(t, []string{"add br0", "add br1"}, receiveEvents(t, events, 2))
+
+	// update3 notifications update the cache and the transaction id
+	server.notify("update3", "since", "txn-2", map[string]interface{}{"Bridge": map[string]interface{}{
+		br0: map[string]interface{}{"modify": map[string]interface{}{
+			"external_ids": []interface{}{"map", []interface{}{[]interface{}{"foo", "bar"}}},
+		}},
+	}})
+	assert.Equal(t, []string{"update br0"}, receiveEvents(t, events, 1))
+	assert.Equal(t, "txn-2", ovs.Cache.LastTransactionID())
+
+	// After a reconnection, only the changes since the last transaction are applied
+	server.dropConnections()
+	assert.Equal(t, []string{"delete br1"}, receiveEvents(t, events, 1))
+	mutex.Lock()
+	assert.Equal(t, "txn-2", txnIDs[1])
+	mutex.Unlock()
+	assert.Eventually(t, func() bool {
+		return ovs.ConnectionState() == StateConnected
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "txn-3", ovs.Cache.LastTransactionID())
+	assert.Equal(t, &bridgeType{UUID: br0, Name: "br0", ExternalIds: map[string]string{"foo": "bar"}},
+		ovs.Cache.Table("Bridge").Row(br0))
+
+	// If the server cannot provide the changes, the cache is reconciled with the full contents
+	server.dropConnections()
+	assert.ElementsMatch(t, []string{"delete br0", "add br2"}, receiveEvents(t, events, 2))
+	assert.Eventually(t, func() bool {
+		return ovs.ConnectionState() == StateConnected
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "txn-9", ovs.Cache.LastTransactionID())
+	assert.Equal(t, []string{br2}, ovs.Cache.Table("Bridge").Rows())
+}
+
+func TestLeaderOnly(t *testing.T) {
+	const database = "2f77b348-9768-4866-b761-89d5177ecdff"
+	databaseRow := func(leader bool, index int) map[string]interface{} {
+		return map[string]interface{}{
+			"name":      "Open_vSwitch",
+			"model":     "clustered",
+			"connected": true,
+			"leader":    leader,
+			"index":     index,
+		}
+	}
+	setStatus := func(s *testOvsdbServer, leader bool, index int) {
+		row := databaseRow(leader, index)
+		s.setRow("Database", database, row)
+		s.notify("update", serverMonitorID, map[string]interface{}{
+			"Database": map[string]interface{}{database: map[string]interface{}{"new": row}},
+		})
+	}
+	serverA := newTestOvsdbServer(t)
+	serverA.setRow("Database", database, databaseRow(false, 10))
+	serverA.start()
+	serverB := newTestOvsdbServer(t)
+	serverB.setRow("Database", database, databaseRow(true, 10))
+	serverB.start()
+
+	ovs, err := Connect(context.Background(), serverA.endpoint()+","+serverB.endpoint(), defDB, nil,
+		WithReconnect(testBackoff()), WithLeaderOnly(true))
+	require.Nil(t, err)
+	defer ovs.Disconnect()
+	assert.Equal(t, 0, serverA.handshakeCount())
+	assert.Equal(t, 1, serverB.handshakeCount())
+
+	// The client moves to the new leader
+	serverA.setRow("Database", database, databaseRow(true, 12))
+	setStatus(serverB, false, 12)
+	assert.Eventually(t, func() bool {
+		return serverA.handshakeCount() == 1 && ovs.ConnectionState() == StateConnected
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// A leader that is behind the last server seen is rejected
+	serverB.setRow("Database", database, databaseRow(true, 11))
+	setStatus(serverA, false, 13)
+	assert.Eventually(t, func() bool {
+		return ovs.ConnectionState() == StateReconnecting
+	}, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, StateReconnecting, ovs.ConnectionState())
+	assert.Equal(t, 1, serverB.handshakeCount())
+
+	serverB.setRow("Database", database, databaseRow(true, 13))
+	assert.Eventually(t, func() bool {
+		return serverB.handshakeCount() == 2 && ovs.ConnectionState() == StateConnected
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Losing the connectivity with the cluster also drops the connection
+	serverA.setRow("Database", database, databaseRow(true, 13))
+	row := databaseRow(true, 13)
+	row["connected"] = false
+	serverB.setRow("Database", database, row)
+	serverB.notify("update", serverMonitorID, map[string]interface{}{
+		"Database": map[string]interface{}{database: map[string]interface{}{"new": row}},
+	})
+	assert.Eventually(t, func() bool {
+		return serverA.handshakeCount() == 2 && ovs.ConnectionState() == StateConnected
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestLeaderOnlyStandalone(t *testing.T) {
+	server := newTestOvsdbServer(t)
+	server.setRow("Database", "2f77b348-9768-4866-b761-89d5177ecdff", map[string]interface{}{
+		"name":      "Open_vSwitch",
+		"model":     "standalone",
+		"connected": true,
+		"leader":    true,
+	})
+	server.start()
+
+	ovs, err := Connect(context.Background(), server.endpoint(), defDB, nil, WithLeaderOnly(true))
+	require.Nil(t, err)
+	ovs.Disconnect()
+
+	// Without the _Server database, the client cannot tell whether the server is the leader
+	server = newTestOvsdbServer(t)
+	server.start()
+	_, err = Connect(context.Background(), server.endpoint(), defDB, nil, WithLeaderOnly(true))
+	assert.NotNil(t, err)
+}
+
+// testLockHandler is a NotificationHandler that reports Locked and Stolen notifications
+type testLockHandler struct {
+	notifications chan string
+}
+
+func (h *testLockHandler) Update(interface{}, ovsdb.TableUpdates) {
+}
+func (h *testLockHandler) Update2(interface{}, ovsdb.TableUpdates2) {
+}
+func (h *testLockHandler) Locked(params []interface{}) {
+	h.notifications <- fmt.Sprintf("locked %v", params[0])
+}
+func (h *testLockHandler) Stolen(params []interface{}) {
+	h.notifications <- fmt.Sprintf("stolen %v", params[0])
+}
+func (h *testLockHandler) Echo([]interface{}) {
+}
+func (h *testLockHandler) Disconnected() {
+}
+
+func TestLock(t *testing.T) {
+	// The server implements a single lock with a queue of waiters
+	var mutex sync.Mutex
+	var owner *rpc2.Client
+	var waiters []*rpc2.Client
+	server := newTestOvsdbServer(t)
+	server.handlers["lock"] = func(c *rpc2.Client, args []interface{}, reply *map[string]bool) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		*reply = map[string]bool{"locked": false}
+		if owner == nil {
+			owner = c
+			(*reply)["locked"] = true
+			return nil
+		}
+		waiters = append(waiters, c)
+		return nil
+	}
+	server.handlers["steal"] = func(c *rpc2.Client, args []interface{}, reply *map[string]bool) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		*reply = map[string]bool{"locked": false}
+		if owner != nil && owner != c {
+			require.Nil(t, owner.Notify("stolen", args))
+			waiters = append([]*rpc2.Client{owner}, waiters...)
+		}
+		owner = c
+		(*reply)["locked"] = true
+		return nil
+	}
+	server.handlers["unlock"] = func(c *rpc2.Client, args []interface{}, reply *map[string]interface{}) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		*reply = map[string]interface{}{}
+		if owner == c {
+			owner = nil
+			if len(waiters) > 0 {
+				owner, waiters = waiters[0], waiters[1:]
+				require.Nil(t, owner.Notify("locked", args))
+			}
+		}
+		return nil
+	}
+	server.start()
+
+	ctx := context.Background()
+	ovs1, err := Connect(ctx, server.endpoint(), defDB, nil)
+	require.Nil(t, err)
+	defer ovs1.Disconnect()
+	handler1 := &testLockHandler{make(chan string, 10)}
+	ovs1.Register(handler1)
+	ovs2, err := Connect(ctx, server.endpoint(), defDB, nil)
+	require.Nil(t, err)
+	defer ovs2.Disconnect()
+	handler2 := &testLockHandler{make(chan string, 10)}
+	ovs2.Register(handler2)
+
+	locked, err := ovs1.Lock(ctx, "lock")
+	require.Nil(t, err)
+	assert.True(t, locked)
+	locked, err = ovs2.Lock(ctx, "lock")
+	require.Nil(t, err)
+	assert.False(t, locked)
+
+	// The lock is handed to the next waiter when released
+	require.Nil(t, ovs1.Unlock(ctx, "lock"))
+	assert.Equal(t, []string{"locked lock"}, receiveEvents(t, handler2.notifications, 1))
+
+	// Stealing the lock notifies the previous owner
+	require.Nil(t, ovs1.Steal(ctx, "lock"))
+	assert.Equal(t, []string{"stolen lock"}, receiveEvents(t, handler2.notifications, 1))
+
+	// and it gets the lock back when released
+	require.Nil(t, ovs1.Unlock(ctx, "lock"))
+	assert.Equal(t, []string{"locked lock"}, receiveEvents(t, handler2.notifications, 1))
+	assert.Len(t, handler1.notifications, 0)
+}
+
+func TestLockReconnect(t *testing.T) {
+	var mutex sync.Mutex
+	var requests int
+	server := newTestOvsdbServer(t)
+	server.handlers["lock"] = func(c *rpc2.Client, args []interface{}, reply *map[string]bool) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		*reply = map[string]bool{"locked": false}
+		requests++
+		(*reply)["locked"] = true
+		return nil
+	}
+	server.start()
+
+	ctx := context.Background()
+	ovs, err := Connect(ctx, server.endpoint(), defDB, nil, WithReconnect(testBackoff()))
+	require.Nil(t, err)
+	defer ovs.Disconnect()
+	handler := &testLockHandler{make(chan string, 10)}
+	ovs.Register(handler)
+
+	locked, err := ovs.Lock(ctx, "lock")
+	require.Nil(t, err)
+	assert.True(t, locked)
+
+	// The lock is lost with the connection and acquired again after reconnecting
+	server.dropConnections()
+	assert.Equal(t, []string{"stolen lock", "locked lock"}, receiveEvents(t, handler.notifications, 2))
+	mutex.Lock()
+	assert.Equal(t, 2, requests)
+	mutex.Unlock()
+}
+
+// testDisconnectHandler is a NotificationHandler that reports Disconnected notifications
+type testDisconnectHandler struct {
+	disconnected chan struct{}
+}
+
+func (h *testDisconnectHandler) Update(interface{}, ovsdb.TableUpdates) {
+}
+func (h *testDisconnectHandler) Update2(interface{}, ovsdb.TableUpdates2) {
+}
+func (h *testDisconnectHandler) Locked([]interface{}) {
+}
+func (h *testDisconnectHandler) Stolen([]interface{}) {
+}
+func (h *testDisconnectHandler) Echo([]interface{}) {
+}
+func (h *testDisconnectHandler) Disconnected() {
+	close(h.disconnected)
+}
+
+// testUpdate2Handler is a NotificationHandler that records Update2 notifications
+type testUpdate2Handler struct {
+	testDisconnectHandler
+	updates []ovsdb.TableUpdates2
+}
+
+func (h *testUpdate2Handler) Update2(_ interface{}, tableUpdates ovsdb.TableUpdates2) {
+	h.updates = append(h.updates, tableUpdates)
+}
+
+func TestOptionalUpdateHandlers(t *testing.T) {
+	const br0 = "2f77b348-9768-4866-b761-89d5177ecda0"
+	var schema ovsdb.DatabaseSchema
+	require.Nil(t, json.Unmarshal(testServerSchema, &schema))
+	ovs, err := NewOvsdbClient(defDB, WithEndpoint("unix:/tmp/db.sock"))
+	require.Nil(t, err)
+	ovs.Cache.setSchema(&schema)
+	update2Handler := &testUpdate2Handler{}
+	// NotificationHandlers do not need to handle Update2 notifications
+	ovs.Register(&testDisconnectHandler{})
+	ovs.Register(update2Handler)
+
+	rows := map[string]interface{}{"Bridge": map[string]interface{}{br0: map[string]interface{}{"insert": map[string]interface{}{"name": "br0"}}}}
+	require.Nil(t, ovs.update2([]interface{}{"cond", rows}))
+	require.Nil(t, ovs.update3([]interface{}{"since", "txn-1", rows}))
+	// handlers that do not implement Update3 receive update3 notifications with Update2
+	assert.Len(t, update2Handler.updates, 2)
+	// while the cache implements it to record the transaction id
+	assert.Equal(t, "txn-1", ovs.Cache.LastTransactionID())
+}
+
+func TestInactivityCheck(t *testing.T) {
+	var mutex sync.Mutex
+	echoes := 0
+	hang := false
+	release := make(chan struct{})
+	defer close(release)
+	server := newTestOvsdbServer(t)
+	server.handlers["echo"] = func(_ *rpc2.Client, args []interface{}, reply *[]interface{}) error {
+		mutex.Lock()
+		echoes++
+		hanging := hang
+		mutex.Unlock()
+		if hanging {
+			<-release
+		}
+		*reply = args
+		return nil
+	}
+	server.start()
+
+	_, err := Connect(context.Background(), server.endpoint(), defDB, nil, WithInactivityCheck(0, time.Second))
+	assert.NotNil(t, err)
+
+	ovs, err := Connect(context.Background(), server.endpoint(), defDB, nil,
+		WithInactivityCheck(20*time.Millisecond, 50*time.Millisecond))
+	require.Nil(t, err)
+	defer ovs.Disconnect()
+	handler := &testDisconnectHandler{make(chan struct{})}
+	ovs.Register(handler)
+
+	// The connection is kept alive while the server answers the echo requests
+	assert.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return echoes >= 3
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, StateConnected, ovs.ConnectionState())
+
+	// and closed when it stops answering
+	mutex.Lock()
+	hang = true
+	mutex.Unlock()
+	select {
+	case <-handler.disconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for disconnection")
+	}
+	assert.Equal(t, StateDisconnected, ovs.ConnectionState())
+}
+
+// testBlockingHandler is a NotificationHandler whose Update blocks until released
+type testBlockingHandler struct {
+	testDisconnectHandler
+	blocked chan struct{}
+	release chan struct{}
+}
+
+func (h *testBlockingHandler) Update(interface{}, ovsdb.TableUpdates) {
+	h.blocked <- struct{}{}
+	<-h.release
+}
+
+func TestInactivityCheckBlockedDelivery(t *testing.T) {
+	server := newTestOvsdbServer(t)
+	server.start()
+	ovs, err := Connect(context.Background(), server.endpoint(), defDB, nil,
+		WithInactivityCheck(20*time.Millisecond, 50*time.Millisecond))
+	require.Nil(t, err)
+	defer ovs.Disconnect()
+	handler := &testBlockingHandler{
+		testDisconnectHandler: testDisconnectHandler{make(chan struct{})},
+		blocked:               make(chan struct{}),
+		release:               make(chan struct{}),
+	}
+	ovs.Register(handler)
+
+	server.notify("update", "all", map[string]interface{}{})
+	<-handler.blocked
+	// the echo replies cannot be read while the notification is being delivered
+	select {
+	case <-handler.disconnected:
+		t.Fatal("the connection was closed while delivering a notification")
+	case <-time.After(300 * time.Millisecond):
+	}
+	close(handler.release)
+	select {
+	case <-handler.disconnected:
+		t.Fatal("the connection was closed after delivering a notification")
+	case <-time.After(300 * time.Millisecond):
+	}
+	assert.Equal(t, StateConnected, ovs.ConnectionState())
+}
+
+func TestNewOvsdbClient(t *testing.T) {
+	const (
+		br0 = "2f77b348-9768-4866-b761-89d5177ecda0"
+		br1 = "2f77b348-9768-4866-b761-89d5177ecda1"
+	)
+	_, err := NewOvsdbClient(defDB)
+	assert.NotNil(t, err)
+
+	server := newTestOvsdbServer(t)
+	server.setRow("Bridge", br0, map[string]interface{}{"name": "br0"})
+	var mutex sync.Mutex
+	var condRequest interface{}
+	server.handlers["monitor_cond"] = func(_ *rpc2.Client, args []interface{}, reply *map[string]interface{}) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		condRequest = args[2]
+		*reply = map[string]interface{}{
+			"Bridge": map[string]interface{}{br1: map[string]interface{}{"initial": map[string]interface{}{"name": "br1"}}},
+		}
+		return nil
+	}
+	server.start()
+
+	ovs, err := NewOvsdbClient(defDB, WithConfig(Config{Addr: server.endpoint()}))
+	require.Nil(t, err)
+	defer ovs.Disconnect()
+	assert.Equal(t, StateDisconnected, ovs.ConnectionState())
+
+	// Handlers and monitors can be registered before connecting
+	events := make(chan string, 10)
+	ovs.Cache.AddEventHandler(&EventHandlerFuncs{
+		AddFunc: func(table string, model Model) {
+			events <- "add " + model.(*bridgeType).Name
+		},
+	})
+	err = ovs.MonitorAll(context.Background(), "all")
+	require.Nil(t, err)
+	bridge := &bridgeType{}
+	err = ovs.MonitorCond(context.Background(), "cond", TableMonitor{
+		Model:      bridge,
+		Fields:     []interface{}{&bridge.Name},
+		Conditions: []Condition{{Field: &bridge.Name, Function: ovsdb.ConditionEqual, Value: "br1"}},
+	})
+	require.Nil(t, err)
+	assert.Nil(t, ovs.Cache.Table("Bridge"))
+
+	err = ovs.Connect(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, StateConnected, ovs.ConnectionState())
+	assert.ElementsMatch(t, []string{br0, br1}, ovs.Cache.Table("Bridge").Rows())
+	assert.ElementsMatch(t, []string{"add br0", "add br1"}, receiveEvents(t, events, 2))
+	mutex.Lock()
+	b, err := json.Marshal(condRequest)
+	mutex.Unlock()
+	require.Nil(t, err)
+	assert.JSONEq(t, `{"Bridge": {"columns": ["name"], "where": [["name", "==", "br1"]],
+		"select": {"initial": true, "insert": true, "delete": true, "modify": true}}}`, string(b))
+
+	// A client can only be connected once
+	err = ovs.Connect(context.Background())
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,169 @@
+package client
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/cenkalti/rpc2"
+)
+
+// errCallAbandoned is the error of the response generated for an abandoned call
+const errCallAbandoned = "call abandoned by the client"
+
+// callArgs wraps the arguments of a call so that callCodec can record its sequence number
+type callArgs struct {
+	args  interface{}
+	codec *callCodec
+	seq   uint64
+}
+
+// MarshalJSON encodes the wrapped arguments
+func (a *callArgs) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.args)
+}
+
+// codecHeader is a header read from the underlying codec
+type codecHeader struct {
+	req  rpc2.Request
+	resp rpc2.Response
+	err  error
+}
+
+// callCodec is a rpc2.Codec that allows abandoning calls. rpc2 keeps every call in a pending
+// map until its response arrives or the connection is closed, so, for each abandoned call,
+// callCodec returns an error response to rpc2 that removes it from the map right away. The
+// response from the server, if it ever arrives, is then discarded by rpc2
+// Headers are read by a separate goroutine so that those responses are not delayed until
+// something is received from the server
+type callCodec struct {
+	rpc2.Codec
+	headers chan codecHeader
+	// next lets the reader goroutine read the next header once the body of the previous one,
+	// which the underlying codec holds until then, has been consumed
+	next      chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	mutex     sync.Mutex
+	abandoned []uint64
+	// abandonedNotify is signaled when a call is added to abandoned
+	abandonedNotify chan struct{}
+
+	// reading is set when the header returned by ReadHeader came from the underlying codec,
+	// and generated when it is the response to an abandoned call. Only used by the read loop
+	reading   bool
+	generated bool
+}
+
+func newCallCodec(codec rpc2.Codec) *callCodec {
+	c := &callCodec{
+		Codec:           codec,
+		headers:         make(chan codecHeader),
+		next:            make(chan struct{}),
+		closed:          make(chan struct{}),
+		abandonedNotify: make(chan struct{}, 1),
+	}
+	go c.readHeaders()
+	return c
+}
+
+// readHeaders reads headers from the underlying codec until it fails or is closed
+func (c *callCodec) readHeaders() {
+	for {
+		var h codecHeader
+		h.err = c.Codec.ReadHeader(&h.req, &h.resp)
+		select {
+		case c.headers <- h:
+		case <-c.closed:
+			return
+		}
+		if h.err != nil {
+			return
+		}
+		select {
+		case <-c.next:
+		case <-c.closed:
+			return
+		}
+	}
+}
+
+// ReadHeader implements the rpc2.Codec interface
+func (c *callCodec) ReadHeader(req *rpc2.Request, resp *rpc2.Response) error {
+	if c.reading {
+		c.reading = false
+		select {
+		case c.next <- struct{}{}:
+		case <-c.closed:
+		}
+	}
+	for {
+		c.mutex.Lock()
+		if len(c.abandoned) > 0 {
+			resp.Seq = c.abandoned[0]
+			resp.Error = errCallAbandoned
+			c.abandoned = c.abandoned[1:]
+			c.mutex.Unlock()
+			c.generated = true
+			return nil
+		}
+		c.mutex.Unlock()
+		select {
+		case h := <-c.headers:
+			*req = h.req
+			*resp = h.resp
+			c.reading = h.err == nil
+			c.generated = false
+			return h.err
+		case <-c.abandonedNotify:
+		case <-c.closed:
+			return io.EOF
+		}
+	}
+}
+
+// ReadRequestBody implements the rpc2.Codec interface
+func (c *callCodec) ReadRequestBody(x interface{}) error {
+	if c.generated {
+		return nil
+	}
+	return c.Codec.ReadRequestBody(x)
+}
+
+// ReadResponseBody implements the rpc2.Codec interface
+func (c *callCodec) ReadResponseBody(x interface{}) error {
+	if c.generated {
+		return nil
+	}
+	return c.Codec.ReadResponseBody(x)
+}
+
+// WriteRequest implements the rpc2.Codec interface
+func (c *callCodec) WriteRequest(r *rpc2.Request, body interface{}) error {
+	if args, ok := body.(*callArgs); ok {
+		args.codec = c
+		args.seq = r.Seq
+		body = args.args
+	}
+	return c.Codec.WriteRequest(r, body)
+}
+
+// Close implements the rpc2.Codec interface
+func (c *callCodec) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+	})
+	return c.Codec.Close()
+}
+
+// abandon makes rpc2 forget the call with the given sequence number
+func (c *callCodec) abandon(seq uint64) {
+	c.mutex.Lock()
+	c.abandoned = append(c.abandoned, seq)
+	c.mutex.Unlock()
+	select {
+	case c.abandonedNotify <- struct{}{}:
+	default:
+	}
+}
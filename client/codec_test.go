@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/cenkalti/rpc2"
+	"github.com/cenkalti/rpc2/jsonrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pendingCalls returns the number of calls rpc2 is waiting a response for
+func pendingCalls(c *rpc2.Client) int {
+	v := reflect.ValueOf(c).Elem()
+	mutex := (*sync.Mutex)(unsafe.Pointer(v.FieldByName("mutex").UnsafeAddr()))
+	mutex.Lock()
+	defer mutex.Unlock()
+	return v.FieldByName("pending").Len()
+}
+
+func TestCallCodecAbandon(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	defer serverConn.Close()
+	// the server only answers echo requests, after answering every request it ignored before
+	go func() {
+		dec := json.NewDecoder(serverConn)
+		enc := json.NewEncoder(serverConn)
+		var ignored []interface{}
+		for {
+			var req struct {
+				ID     interface{}   `json:"id"`
+				Method string        `json:"method"`
+				Params []interface{} `json:"params"`
+			}
+			if err := dec.Decode(&req); err != nil {
+				return
+			}
+			if req.Method != "echo" {
+				ignored = append(ignored, req.ID)
+				continue
+			}
+			for _, id := range ignored {
+				_ = enc.Encode(map[string]interface{}{"id": id, "result": []interface{}{"late"}, "error": nil})
+			}
+			ignored = nil
+			_ = enc.Encode(map[string]interface{}{"id": req.ID, "result": req.Params, "error": nil})
+		}
+	}()
+
+	rpcClient := rpc2.NewClientWithCodec(newCallCodec(jsonrpc.NewJSONCodec(clientConn)))
+	go rpcClient.Run()
+	defer rpcClient.Close()
+
+	for i := 0; i < 10; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		var reply []interface{}
+		err := callClient(ctx, rpcClient, "transact", []interface{}{"Open_vSwitch"}, &reply)
+		cancel()
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Nil(t, reply)
+	}
+	// the abandoned calls do not accumulate while the server does not answer them
+	assert.Eventually(t, func() bool { return pendingCalls(rpcClient) == 0 }, time.Second, 10*time.Millisecond)
+
+	// and their late responses are discarded
+	var reply []interface{}
+	require.Nil(t, callClient(context.Background(), rpcClient, "echo", []interface{}{"libovsdb echo"}, &reply))
+	assert.Equal(t, []interface{}{"libovsdb echo"}, reply)
+	assert.Equal(t, 0, pendingCalls(rpcClient))
+}
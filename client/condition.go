@@ -19,6 +19,20 @@ type Conditional interface {
 	Table() string
 }
 
+// indexedConditional is implemented by the Conditionals that can find the
+// cached models they match using the indexes of the cache
+type indexedConditional interface {
+	// rows returns the UUIDs of the rows that match the condition. It returns
+	// false if the RowCache is not indexed
+	rows(tableCache *RowCache) ([]string, bool, error)
+}
+
+// modelConditional is implemented by the Conditionals created from a model
+type modelConditional interface {
+	// baseModel returns the model whose fields the conditions refer to
+	baseModel() Model
+}
+
 // equalityConditional uses the information available in a model to generate conditions
 // The conditions are based on the equality of the first available index.
 // The priority of indexes is: uuid, {schema index}
@@ -37,6 +51,17 @@ func (c *equalityConditional) Table() string {
 	return c.tableName
 }
 
+func (c *equalityConditional) baseModel() Model {
+	return c.model
+}
+
+// rows returns the rows that have the same value as the model in the _uuid or any of the
+// indexes of the schema, which are the ones Generate can use. Client indexes are left out
+// so that the cached rows are the ones the generated conditions select in the server
+func (c *equalityConditional) rows(tableCache *RowCache) ([]string, bool, error) {
+	return tableCache.rowsByModel(c.model, false)
+}
+
 // Generate returns a condition based on the model and the field pointers
 func (c *equalityConditional) Generate() ([][]ovsdb.Condition, error) {
 	var result [][]ovsdb.Condition
@@ -74,14 +99,54 @@ type explicitConditional struct {
 	singleOp   bool
 }
 
+// Matches evaluates the conditions against the model as the server would. The model must
+// match all of them if the Conditional was created with WhereAll and any of them otherwise
 func (c *explicitConditional) Matches(m Model) (bool, error) {
-	return false, fmt.Errorf("Cannot perform Cache comparisons using explicit Conditions")
+	table := c.orm.schema.Table(c.tableName)
+	if table == nil {
+		return false, NewErrNoTable(c.tableName)
+	}
+	condInfo, err := newORMInfo(table, c.model)
+	if err != nil {
+		return false, err
+	}
+	info, err := newORMInfo(table, m)
+	if err != nil {
+		return false, err
+	}
+	for _, cond := range c.conditions {
+		column, err := condInfo.columnByPtr(cond.Field)
+		if err != nil {
+			return false, err
+		}
+		columnSchema := table.Column(column)
+		if columnSchema == nil {
+			return false, fmt.Errorf("column %s not found", column)
+		}
+		actual, err := info.fieldByColumn(column)
+		if err != nil {
+			return false, err
+		}
+		matches, err := ovsdb.EvaluateCondition(columnSchema, cond.Function, actual, cond.Value)
+		if err != nil {
+			return false, err
+		}
+		if matches != c.singleOp {
+			// a condition that does not match when all must, or one that does when any can
+			return matches, nil
+		}
+	}
+	return c.singleOp, nil
 }
 
 func (c *explicitConditional) Table() string {
 	return c.tableName
 }
 
+func (c *explicitConditional) baseModel() Model {
+	return c.model
+}
+
 // Generate returns a condition based on the model and the field pointers
 func (c *explicitConditional) Generate() ([][]ovsdb.Condition, error) {
 	var result [][]ovsdb.Condition
@@ -145,15 +210,16 @@ func (c *predicateConditional) Generate() ([][]ovsdb.Condition, error) {
 	}
 	for _, row := range tableCache.Rows() {
 		elem := tableCache.Row(row)
+		if elem == nil {
+			continue
+		}
 		match, err := c.Matches(elem)
 		if err != nil {
 			return nil, err
 		}
 		if match {
-			elemCond, err := c.cache.orm.newEqualityCondition(c.tableName, elem)
-			if err != nil {
-				return nil, err
-			}
+			// Rows are cached by UUID, so there is no need to look for an index in the model
+			elemCond := []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: row})}
 			allConditions = append(allConditions, elemCond)
 		}
 	}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/ovn-org/libovsdb/ovsdb"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestEqualityConditional(t *testing.T) {
@@ -426,7 +427,7 @@ func TestExplicitConditional(t *testing.T) {
 			cond, err := newExplicitConditional(cache.orm, "Logical_Switch_Port", tt.all, testObj, tt.args...)
 			assert.Nil(t, err)
 			_, err = cond.Matches(testObj)
-			assert.NotNilf(t, err, "Explicit conditions should fail to match on cache")
+			assert.Nil(t, err)
 			generated, err := cond.Generate()
 			if tt.err {
 				assert.NotNil(t, err)
@@ -437,3 +438,103 @@ func TestExplicitConditional(t *testing.T) {
 		})
 	}
 }
+
+func TestExplicitConditionalMatches(t *testing.T) {
+	cache := apiTestCache(t)
+	lsps := []*testLogicalSwitchPort{
+		{UUID: aUUID0, Name: "lsp0", ExternalIds: map[string]string{"foo": "bar"}, Enabled: []bool{true}, Tag: []int{1}},
+		{UUID: aUUID1, Name: "lsp1", ExternalIds: map[string]string{"foo": "baz"}, Enabled: []bool{false}, Tag: []int{2}},
+		{UUID: aUUID2, Name: "lsp2", ExternalIds: map[string]string{"unique": "id"}, Enabled: []bool{false}},
+		{UUID: aUUID3, Name: "lsp3", ExternalIds: map[string]string{"foo": "baz"}, Enabled: []bool{true}, Tag: []int{1, 2}},
+	}
+	lspCache := cache.newRowCache("Logical_Switch_Port")
+	for _, lsp := range lsps {
+		lspCache.set(lsp.UUID, lsp)
+	}
+	cache.cache["Logical_Switch_Port"] = lspCache
+	a := newAPI(cache)
+	testObj := &testLogicalSwitchPort{}
+
+	tests := []struct {
+		name     string
+		conds    []Condition
+		all      bool
+		expected []string
+		err      bool
+	}{
+		{
+			name:     "not equal",
+			conds:    []Condition{{Field: &testObj.Name, Function: ovsdb.ConditionNotEqual, Value: "lsp0"}},
+			expected: []string{"lsp1", "lsp2", "lsp3"},
+		},
+		{
+			name:     "uuid",
+			conds:    []Condition{{Field: &testObj.UUID, Function: ovsdb.ConditionEqual, Value: aUUID2}},
+			expected: []string{"lsp2"},
+		},
+		{
+			name:     "map includes",
+			conds:    []Condition{{Field: &testObj.ExternalIds, Function: ovsdb.ConditionIncludes, Value: map[string]string{"foo": "baz"}}},
+			expected: []string{"lsp1", "lsp3"},
+		},
+		{
+			name:     "map excludes",
+			conds:    []Condition{{Field: &testObj.ExternalIds, Function: ovsdb.ConditionExcludes, Value: map[string]string{"foo": "baz"}}},
+			expected: []string{"lsp0", "lsp2"},
+		},
+		{
+			name:     "set includes",
+			conds:    []Condition{{Field: &testObj.Tag, Function: ovsdb.ConditionIncludes, Value: []int{2}}},
+			expected: []string{"lsp1", "lsp3"},
+		},
+		{
+			name: "any",
+			conds: []Condition{
+				{Field: &testObj.Enabled, Function: ovsdb.ConditionEqual, Value: []bool{true}},
+				{Field: &testObj.Name, Function: ovsdb.ConditionEqual, Value: "lsp2"},
+			},
+			expected: []string{"lsp0", "lsp2", "lsp3"},
+		},
+		{
+			name: "all",
+			conds: []Condition{
+				{Field: &testObj.Enabled, Function: ovsdb.ConditionEqual, Value: []bool{true}},
+				{Field: &testObj.Name, Function: ovsdb.ConditionNotEqual, Value: "lsp0"},
+			},
+			all:      true,
+			expected: []string{"lsp3"},
+		},
+		{
+			name:  "invalid function",
+			conds: []Condition{{Field: &testObj.Name, Function: ovsdb.ConditionGreaterThan, Value: "lsp0"}},
+			err:   true,
+		},
+		{
+			name:  "wrong type",
+			conds: []Condition{{Field: &testObj.Name, Function: ovsdb.ConditionEqual, Value: 0}},
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var conditional ConditionalAPI
+			if tt.all {
+				conditional = a.WhereAll(testObj, tt.conds...)
+			} else {
+				conditional = a.Where(testObj, tt.conds...)
+			}
+			var result []testLogicalSwitchPort
+			err := conditional.List(&result)
+			if tt.err {
+				assert.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			var names []string
+			for _, lsp := range result {
+				names = append(names, lsp.Name)
+			}
+			assert.ElementsMatch(t, tt.expected, names)
+		})
+	}
+}
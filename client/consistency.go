@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// TransactAndWait works like Transact but, if every operation succeeds, it also waits until the
+// cache reflects the changes made by the transaction, so that they can be read right away with
+// Get or List. The context bounds both the transaction and the wait, if it expires while waiting
+// the results are returned along with the error of the context.
+// Rows are tracked by UUID: the ones inserted must be in the cache, the ones deleted must not
+// and the ones updated must have the new values. Operations on rows that are not identified by
+// their UUID, mutations and operations on tables that are not monitored are not tracked, but as the
+// server notifies all the changes of a transaction at once, they are applied with the tracked ones.
+// Changes to rows that do not match the conditions of the monitors are never applied to the cache,
+// so the wait only ends when the context does
+func (ovs *OvsdbClient) TransactAndWait(ctx context.Context, operation ...ovsdb.Operation) ([]ovsdb.OperationResult, error) {
+	reply, err := ovs.Transact(ctx, operation...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ovsdb.CheckOperationResults(reply, operation); err != nil {
+		// The transaction was aborted, there is nothing to wait for
+		return reply, nil
+	}
+	cond := ovs.cacheConsistent(operation, reply)
+	if err := ovs.Cache.waitFor(ctx, cond); err != nil {
+		return reply, fmt.Errorf("waiting for the cache to reflect the transaction: %w", err)
+	}
+	return reply, nil
+}
+
+// cacheConsistent returns a function that tells whether the cache reflects the changes
+// made by the operations, which resulted in the given results
+func (ovs *OvsdbClient) cacheConsistent(operations []ovsdb.Operation, results []ovsdb.OperationResult) func() (bool, error) {
+	monitored := ovs.monitoredColumns()
+	var checks []func() (bool, error)
+	for i, op := range operations {
+		columns, ok := monitored[op.Table]
+		if !ok {
+			continue
+		}
+		table, op := op.Table, op
+		switch op.Op {
+		case opInsert:
+			uuid := results[i].UUID.GoUUID
+			if uuid == "" {
+				continue
+			}
+			checks = append(checks, func() (bool, error) {
+				return ovs.cachedRow(table, uuid) != nil, nil
+			})
+		case opDelete:
+			uuid := whereUUID(op.Where)
+			if uuid == "" {
+				continue
+			}
+			checks = append(checks, func() (bool, error) {
+				return ovs.cachedRow(table, uuid) == nil, nil
+			})
+		case opUpdate:
+			uuid := whereUUID(op.Where)
+			if uuid == "" {
+				continue
+			}
+			checks = append(checks, func() (bool, error) {
+				model := ovs.cachedRow(table, uuid)
+				if model == nil {
+					return false, nil
+				}
+				return ovs.Cache.orm.rowEqual(table, model, op.Row, columns)
+			})
+		}
+	}
+	return func() (bool, error) {
+		for _, check := range checks {
+			if ok, err := check(); err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+}
+
+// monitoredColumns returns the columns monitored in each table. A nil set of columns means
+// that every column is monitored
+func (ovs *OvsdbClient) monitoredColumns() map[string]map[string]bool {
+	ovs.monitorsMutex.Lock()
+	defer ovs.monitorsMutex.Unlock()
+	monitored := make(map[string]map[string]bool)
+	for _, m := range ovs.monitors {
+		for table, request := range m.requests {
+			columns, ok := monitored[table]
+			if ok && columns == nil {
+				continue
+			}
+			if len(request.Columns) == 0 {
+				monitored[table] = nil
+				continue
+			}
+			if columns == nil {
+				columns = make(map[string]bool)
+				monitored[table] = columns
+			}
+			for _, column := range request.Columns {
+				columns[column] = true
+			}
+		}
+	}
+	return monitored
+}
+
+// cachedRow returns the cached model of a row or nil if it is not in the cache
+func (ovs *OvsdbClient) cachedRow(table, uuid string) Model {
+	tableCache := ovs.Cache.Table(table)
+	if tableCache == nil {
+		return nil
+	}
+	return tableCache.Row(uuid)
+}
+
+// whereUUID returns the UUID a list of conditions matches if it includes an equality
+// condition on the _uuid column
+func whereUUID(where []ovsdb.Condition) string {
+	for _, cond := range where {
+		if cond.Column != "_uuid" || cond.Function != ovsdb.ConditionEqual {
+			continue
+		}
+		switch v := cond.Value.(type) {
+		case ovsdb.UUID:
+			return v.GoUUID
+		case string:
+			return v
+		}
+	}
+	return ""
+}
+
+// rowEqual tells whether the given columns of the model have the values in row
+// If columns is nil, every column in row is compared
+func (o orm) rowEqual(tableName string, model Model, row map[string]interface{}, columns map[string]bool) (bool, error) {
+	table := o.schema.Table(tableName)
+	if table == nil {
+		return false, NewErrNoTable(tableName)
+	}
+	info, err := newORMInfo(table, model)
+	if err != nil {
+		return false, err
+	}
+	for column, ovsValue := range row {
+		if columns != nil && !columns[column] {
+			continue
+		}
+		columnSchema := table.Column(column)
+		if columnSchema == nil || !info.hasColumn(column) {
+			continue
+		}
+		value, err := ovsdb.OvsToNative(columnSchema, ovsValue)
+		if err != nil {
+			return false, err
+		}
+		current, err := info.fieldByColumn(column)
+		if err != nil {
+			return false, err
+		}
+		if !nativeEqual(value, current) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// nativeEqual compares two native values. Sets are compared regardless of the order
+// of their elements and empty and nil sets or maps are considered equal
+func nativeEqual(a, b interface{}) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Kind() != vb.Kind() {
+		return false
+	}
+	switch va.Kind() {
+	case reflect.Slice:
+		if va.Len() != vb.Len() {
+			return false
+		}
+		for i := 0; i < va.Len(); i++ {
+			if !sliceContains(vb, va.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if va.Len() == 0 && vb.Len() == 0 {
+			return true
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/rpc2"
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactAndWait(t *testing.T) {
+	const (
+		br0 = "2f77b348-9768-4866-b761-89d5177ecda0"
+		br1 = "2f77b348-9768-4866-b761-89d5177ecda1"
+	)
+	server := newTestOvsdbServer(t)
+	server.setRow("Bridge", br0, map[string]interface{}{"name": "br0", "ports": []interface{}{"set", []interface{}{}}})
+	// notifications are sent after replying to each transaction, updates[i] follows the ith one
+	var updates []map[string]interface{}
+	transactions := 0
+	server.handlers["transact"] = func(_ *rpc2.Client, args []interface{}, reply *[]interface{}) error {
+		*reply = []interface{}{map[string]interface{}{"uuid": []interface{}{"uuid", br1}}}
+		if transactions < len(updates) {
+			update := updates[transactions]
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				server.notify("update", "all", update)
+			}()
+		}
+		transactions++
+		return nil
+	}
+	server.start()
+
+	ovs, err := Connect(context.Background(), server.endpoint(), defDB, nil)
+	require.Nil(t, err)
+	defer ovs.Disconnect()
+	require.Nil(t, ovs.MonitorAll(context.Background(), "all"))
+
+	updates = []map[string]interface{}{
+		{"Bridge": map[string]interface{}{br1: map[string]interface{}{"new": map[string]interface{}{"name": "br1"}}}},
+		{"Bridge": map[string]interface{}{br0: map[string]interface{}{"new": map[string]interface{}{
+			"name": "br0", "ports": []interface{}{"set", []interface{}{[]interface{}{"uuid", br1}, []interface{}{"uuid", br0}}},
+		}}}},
+		{"Bridge": map[string]interface{}{br0: map[string]interface{}{}}},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	txn := ovs.NewTransaction()
+	bridge := &bridgeType{UUID: "br1", Name: "br1"}
+	require.Nil(t, txn.Create(bridge))
+	uuids, err := txn.CommitAndWait(ctx)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{"br1": br1}, uuids)
+	assert.NotNil(t, ovs.Cache.Table("Bridge").Row(br1))
+
+	// sets are compared regardless of their order
+	_, err = ovs.TransactAndWait(ctx, ovsdb.Operation{
+		Op:    opUpdate,
+		Table: "Bridge",
+		Row: map[string]interface{}{"ports": ovsdb.OvsSet{GoSet: []interface{}{
+			ovsdb.UUID{GoUUID: br0}, ovsdb.UUID{GoUUID: br1},
+		}}},
+		Where: []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: br0})},
+	})
+	require.Nil(t, err)
+	assert.ElementsMatch(t, []string{br0, br1}, ovs.Cache.Table("Bridge").Row(br0).(*bridgeType).Ports)
+
+	_, err = ovs.TransactAndWait(ctx, ovsdb.Operation{
+		Op:    opDelete,
+		Table: "Bridge",
+		Where: []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: br0})},
+	})
+	require.Nil(t, err)
+	assert.Nil(t, ovs.Cache.Table("Bridge").Row(br0))
+
+	// without notification, the wait ends with the context
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	reply, err := ovs.TransactAndWait(ctx, ovsdb.Operation{
+		Op:    opDelete,
+		Table: "Bridge",
+		Where: []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: br1})},
+	})
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Len(t, reply, 1)
+}
+
+func TestNativeEqual(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  interface{}
+		equal bool
+	}{
+		{"strings", "foo", "foo", true},
+		{"different strings", "foo", "bar", false},
+		{"sets in different order", []string{"a", "b"}, []string{"b", "a"}, true},
+		{"different sets", []string{"a", "b"}, []string{"a", "c"}, false},
+		{"empty and nil set", []string{}, []string(nil), true},
+		{"empty and nil map", map[string]string{}, map[string]string(nil), true},
+		{"maps", map[string]string{"a": "b"}, map[string]string{"a": "b"}, true},
+		{"different maps", map[string]string{"a": "b"}, map[string]string{"a": "c"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.equal, nativeEqual(tt.a, tt.b))
+		})
+	}
+}
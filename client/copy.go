@@ -0,0 +1,88 @@
+package client
+
+import (
+	"reflect"
+)
+
+// copyModel returns a deep copy of a model, which shares no maps or slices with it
+func copyModel(model Model) Model {
+	return deepCopy(reflect.ValueOf(model)).Interface().(Model)
+}
+
+// deepCopy returns a copy of a value that does not share memory with it
+// The API hands out copies of the models in the cache, so that the callers can
+// modify them without corrupting the cache or racing with its updates
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Struct:
+		// unexported fields can only be copied as they are
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		if isShallow(v.Type().Elem()) {
+			reflect.Copy(c, v)
+			return c
+		}
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		shallow := isShallow(v.Type().Key()) && isShallow(v.Type().Elem())
+		iter := v.MapRange()
+		for iter.Next() {
+			if shallow {
+				c.SetMapIndex(iter.Key(), iter.Value())
+			} else {
+				c.SetMapIndex(deepCopy(iter.Key()), deepCopy(iter.Value()))
+			}
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem()))
+		return c
+	default:
+		return v
+	}
+}
+
+// isShallow returns whether the values of a type can be copied by assignment
+func isShallow(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Struct, reflect.Slice, reflect.Map, reflect.Interface, reflect.Array:
+		return false
+	default:
+		return true
+	}
+}
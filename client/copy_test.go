@@ -0,0 +1,140 @@
+package client
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeepCopy(t *testing.T) {
+	tests := []struct {
+		name  string
+		model Model
+	}{
+		{
+			name:  "empty",
+			model: &bridgeType{},
+		},
+		{
+			name: "nil and empty values",
+			model: &bridgeType{
+				Name:        "br0",
+				OtherConfig: map[string]string{},
+				Ports:       []string{},
+			},
+		},
+		{
+			name: "full",
+			model: &bridgeType{
+				UUID:        aUUID0,
+				Name:        "br0",
+				OtherConfig: map[string]string{"foo": "bar"},
+				ExternalIds: map[string]string{"owner": "me"},
+				Ports:       []string{aUUID1, aUUID2},
+				Status:      map[string]string{"status": "ok"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := copyModel(tt.model)
+			require.Equal(t, tt.model, c)
+			assert.True(t, tt.model != c)
+			b := c.(*bridgeType)
+			if b.ExternalIds == nil || len(b.Ports) == 0 {
+				return
+			}
+			b.ExternalIds["owner"] = "someone else"
+			b.Ports[0] = aUUID3
+			assert.Equal(t, "me", tt.model.(*bridgeType).ExternalIds["owner"])
+			assert.Equal(t, aUUID1, tt.model.(*bridgeType).Ports[0])
+		})
+	}
+}
+
+func TestAPIReadsCopy(t *testing.T) {
+	cache := apiTestCache(t)
+	lsp := &testLogicalSwitchPort{
+		UUID:        aUUID0,
+		Name:        "lsp0",
+		Addresses:   []string{"foo"},
+		ExternalIds: map[string]string{"foo": "bar"},
+	}
+	lspCache := cache.newRowCache("Logical_Switch_Port")
+	lspCache.set(lsp.UUID, lsp)
+	cache.cache["Logical_Switch_Port"] = lspCache
+	a := newAPI(cache)
+	modify := func(lsp *testLogicalSwitchPort) {
+		lsp.Addresses[0] = "bar"
+		lsp.ExternalIds["foo"] = "baz"
+	}
+
+	var lsps []testLogicalSwitchPort
+	require.Nil(t, a.List(&lsps))
+	require.Len(t, lsps, 1)
+	modify(&lsps[0])
+
+	got := &testLogicalSwitchPort{UUID: aUUID0}
+	require.Nil(t, a.Get(got))
+	modify(got)
+
+	copied := lspCache.RowCopy(aUUID0).(*testLogicalSwitchPort)
+	modify(copied)
+
+	assert.Equal(t, []string{"foo"}, lsp.Addresses)
+	assert.Equal(t, map[string]string{"foo": "bar"}, lsp.ExternalIds)
+	assert.True(t, lsp == lspCache.Row(aUUID0))
+}
+
+func benchmarkCache(b *testing.B, rows int) *TableCache {
+	cache := apiTestCache(b)
+	lspCache := cache.newRowCache("Logical_Switch_Port")
+	for i := 0; i < rows; i++ {
+		uuid := fmt.Sprintf("2f77b348-9768-4866-b761-%012d", i)
+		lspCache.set(uuid, &testLogicalSwitchPort{
+			UUID:        uuid,
+			Name:        fmt.Sprintf("lsp%d", i),
+			Addresses:   []string{"00:00:00:00:00:01 10.0.0.1"},
+			Options:     map[string]string{"requested-chassis": "chassis"},
+			ExternalIds: map[string]string{"owner": "me", "pod": "default/pod"},
+		})
+	}
+	cache.cache["Logical_Switch_Port"] = lspCache
+	return cache
+}
+
+func BenchmarkCopyModel(b *testing.B) {
+	lsp := &testLogicalSwitchPort{
+		Name:        "lsp0",
+		Addresses:   []string{"00:00:00:00:00:01 10.0.0.1"},
+		Options:     map[string]string{"requested-chassis": "chassis"},
+		ExternalIds: map[string]string{"owner": "me", "pod": "default/pod"},
+	}
+	for i := 0; i < b.N; i++ {
+		copyModel(lsp)
+	}
+}
+
+func BenchmarkAPIList(b *testing.B) {
+	a := newAPI(benchmarkCache(b, 1000))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var lsps []testLogicalSwitchPort
+		if err := a.List(&lsps); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAPIGet(b *testing.B) {
+	a := newAPI(benchmarkCache(b, 1000))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lsp := &testLogicalSwitchPort{Name: "lsp500"}
+		if err := a.Get(lsp); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
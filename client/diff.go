@@ -0,0 +1,193 @@
+package client
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// UpdateDiff returns the operations needed to turn the rows selected by the condition from
+// the old model, usually a copy of the cached one, into the new one. Only the columns that
+// differ are written: sets and maps that can be mutated are changed with mutate operations
+// that insert and delete individual elements or keys, so that concurrent changes to the
+// other elements or keys are kept, and the rest of the columns with an update operation
+// Snapshots do not include ephemeral columns, so, until the contents of a table loaded from
+// a snapshot are reconciled with the server's, the ephemeral columns the old model has no
+// value for are left out: their value in the server is not known and it would be overwritten
+func (a api) UpdateDiff(oldModel, newModel Model) ([]ovsdb.Operation, error) {
+	table, err := a.getTableFromModel(newModel)
+	if err != nil {
+		return nil, err
+	}
+	if oldTable, err := a.getTableFromModel(oldModel); err != nil {
+		return nil, err
+	} else if oldTable != table {
+		return nil, &InputTypeError{reflect.TypeOf(oldModel),
+			fmt.Sprintf("Table of the old model (%s) does not match the one of the new model (%s)", oldTable, table)}
+	}
+
+	conditions, err := a.cond.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	row, mutations, err := a.diffModels(table, oldModel, newModel)
+	if err != nil {
+		return nil, err
+	}
+
+	var operations []ovsdb.Operation
+	if len(row) == 0 && len(mutations) == 0 {
+		return operations, nil
+	}
+	for _, condition := range conditions {
+		operations = append(operations, a.waitOperations(condition)...)
+		if len(row) > 0 {
+			operations = append(operations, ovsdb.Operation{
+				Op:    opUpdate,
+				Table: table,
+				Row:   row,
+				Where: condition,
+			})
+		}
+		if len(mutations) > 0 {
+			operations = append(operations, ovsdb.Operation{
+				Op:        opMutate,
+				Table:     table,
+				Mutations: mutations,
+				Where:     condition,
+			})
+		}
+	}
+	return operations, nil
+}
+
+// diffModels returns the row to update and the mutations to apply to transform
+// the old model into the new one. Both are empty if the models are equal
+func (a api) diffModels(table string, oldModel, newModel Model) (map[string]interface{}, []interface{}, error) {
+	tableSchema := a.cache.orm.schema.Table(table)
+	if tableSchema == nil {
+		return nil, nil, NewErrNoTable(table)
+	}
+	oldInfo, err := newORMInfo(tableSchema, oldModel)
+	if err != nil {
+		return nil, nil, err
+	}
+	newInfo, err := newORMInfo(tableSchema, newModel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fromSnapshot := a.cache.fromSnapshot(table)
+	row := make(map[string]interface{})
+	var mutations []interface{}
+	for name, column := range tableSchema.Columns {
+		if !newInfo.hasColumn(name) {
+			continue
+		}
+		oldValue, err := oldInfo.fieldByColumn(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		newValue, err := newInfo.fieldByColumn(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		if fromSnapshot && column.Ephemeral() && ovsdb.IsDefaultValue(column, oldValue) {
+			continue
+		}
+		if writable, err := a.writableColumn(table, tableSchema, name); err != nil {
+			return nil, nil, err
+		} else if !writable {
+			continue
+		}
+
+		if !mutableCollection(column) {
+			ovsValue, err := ovsdb.NativeToOvs(column, newValue)
+			if err != nil {
+				return nil, nil, fmt.Errorf("table %s, column %s: failed to generate ovs element. %s", table, name, err.Error())
+			}
+			row[name] = ovsValue
+			continue
+		}
+
+		deleted, inserted := diffCollection(reflect.ValueOf(oldValue), reflect.ValueOf(newValue))
+		// deletions go first so that the keys of a map whose value changed can be inserted again
+		if deleted.Len() > 0 {
+			mutation, err := a.cache.orm.newMutation(table, newModel, name, ovsdb.MutateOperationDelete, deleted.Interface())
+			if err != nil {
+				return nil, nil, err
+			}
+			mutations = append(mutations, mutation)
+		}
+		if inserted.Len() > 0 {
+			mutation, err := a.cache.orm.newMutation(table, newModel, name, ovsdb.MutateOperationInsert, inserted.Interface())
+			if err != nil {
+				return nil, nil, err
+			}
+			mutations = append(mutations, mutation)
+		}
+	}
+	return row, mutations, nil
+}
+
+// mutableCollection returns whether a column is a set or a map whose elements can be
+// inserted and deleted one by one without violating the limits of its size
+func mutableCollection(column *ovsdb.ColumnSchema) bool {
+	if !column.Mutable() || (column.Type != ovsdb.TypeSet && column.Type != ovsdb.TypeMap) {
+		return false
+	}
+	max := column.TypeObj.Max()
+	return column.TypeObj.Min() == 0 && (max == ovsdb.Unlimited || max > 1)
+}
+
+// diffCollection returns the elements to delete from and insert into the old value
+// of a set (slice) or a map to get the new one. For maps, the keys to delete are
+// returned as a slice and include the ones whose value changed
+func diffCollection(oldValue, newValue reflect.Value) (reflect.Value, reflect.Value) {
+	if oldValue.Kind() == reflect.Map {
+		deleted := reflect.MakeSlice(reflect.SliceOf(oldValue.Type().Key()), 0, 0)
+		inserted := reflect.MakeMap(newValue.Type())
+		iter := oldValue.MapRange()
+		for iter.Next() {
+			value := newValue.MapIndex(iter.Key())
+			if !value.IsValid() || !reflect.DeepEqual(value.Interface(), iter.Value().Interface()) {
+				deleted = reflect.Append(deleted, iter.Key())
+			}
+		}
+		iter = newValue.MapRange()
+		for iter.Next() {
+			value := oldValue.MapIndex(iter.Key())
+			if !value.IsValid() || !reflect.DeepEqual(value.Interface(), iter.Value().Interface()) {
+				inserted.SetMapIndex(iter.Key(), iter.Value())
+			}
+		}
+		return deleted, inserted
+	}
+
+	contains := func(set reflect.Value, elem reflect.Value) bool {
+		for i := 0; i < set.Len(); i++ {
+			if reflect.DeepEqual(set.Index(i).Interface(), elem.Interface()) {
+				return true
+			}
+		}
+		return false
+	}
+	deleted := reflect.MakeSlice(oldValue.Type(), 0, 0)
+	for i := 0; i < oldValue.Len(); i++ {
+		if !contains(newValue, oldValue.Index(i)) {
+			deleted = reflect.Append(deleted, oldValue.Index(i))
+		}
+	}
+	inserted := reflect.MakeSlice(newValue.Type(), 0, 0)
+	for i := 0; i < newValue.Len(); i++ {
+		if !contains(oldValue, newValue.Index(i)) {
+			inserted = reflect.Append(inserted, newValue.Index(i))
+		}
+	}
+	return deleted, inserted
+}
@@ -0,0 +1,181 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIUpdateDiff(t *testing.T) {
+	cache := apiTestCache(t)
+	a := newAPI(cache)
+	old := testLogicalSwitchPort{
+		UUID:        aUUID0,
+		Name:        "lsp0",
+		Type:        "someType",
+		Addresses:   []string{"a", "b"},
+		ExternalIds: map[string]string{"foo": "bar", "baz": "qux", "keep": "me"},
+		Enabled:     []bool{true},
+	}
+	where := []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: aUUID0})}
+
+	tests := []struct {
+		name     string
+		update   func(*testLogicalSwitchPort)
+		row      ovsdb.Row
+		mutation [][]interface{}
+	}{
+		{
+			name:   "no change",
+			update: func(*testLogicalSwitchPort) {},
+		},
+		{
+			name: "atomic columns",
+			update: func(lsp *testLogicalSwitchPort) {
+				lsp.Type = ""
+				lsp.Enabled = []bool{false}
+			},
+			row: ovsdb.Row{Fields: map[string]interface{}{
+				"type":    "",
+				"enabled": testOvsSet(t, []bool{false}),
+			}},
+		},
+		{
+			name: "set elements",
+			update: func(lsp *testLogicalSwitchPort) {
+				lsp.Addresses = []string{"b", "c"}
+			},
+			mutation: [][]interface{}{
+				{"addresses", ovsdb.MutateOperationDelete, testOvsSet(t, []string{"a"})},
+				{"addresses", ovsdb.MutateOperationInsert, testOvsSet(t, []string{"c"})},
+			},
+		},
+		{
+			name: "map keys",
+			update: func(lsp *testLogicalSwitchPort) {
+				lsp.ExternalIds = map[string]string{"foo": "changed", "keep": "me", "new": "key"}
+			},
+			mutation: [][]interface{}{
+				{"external_ids", ovsdb.MutateOperationDelete, testOvsSet(t, []string{"foo"})},
+				{"external_ids", ovsdb.MutateOperationDelete, testOvsSet(t, []string{"baz"})},
+				{"external_ids", ovsdb.MutateOperationInsert, testOvsMap(t, map[string]string{"foo": "changed", "new": "key"})},
+			},
+		},
+		{
+			name: "everything",
+			update: func(lsp *testLogicalSwitchPort) {
+				lsp.Name = "lsp1"
+				lsp.Addresses = nil
+			},
+			row: ovsdb.Row{Fields: map[string]interface{}{"name": "lsp1"}},
+			mutation: [][]interface{}{
+				{"addresses", ovsdb.MutateOperationDelete, testOvsSet(t, []string{"a", "b"})},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldModel := old
+			newModel := copyModel(&old).(*testLogicalSwitchPort)
+			tt.update(newModel)
+			ops, err := a.Where(&testLogicalSwitchPort{UUID: aUUID0}).UpdateDiff(&oldModel, newModel)
+			require.Nil(t, err)
+
+			var row map[string]interface{}
+			var mutations [][]interface{}
+			for _, op := range ops {
+				assert.Equal(t, "Logical_Switch_Port", op.Table)
+				assert.Equal(t, where, op.Where)
+				switch op.Op {
+				case opUpdate:
+					assert.Nil(t, row, "a single update is expected")
+					row = op.Row
+				case opMutate:
+					assert.Nil(t, mutations, "a single mutate is expected")
+					for _, mutation := range op.Mutations {
+						mutations = append(mutations, mutation.([]interface{}))
+					}
+				default:
+					t.Fatalf("unexpected operation %s", op.Op)
+				}
+			}
+			if tt.row.Fields == nil {
+				assert.Nil(t, row)
+			} else {
+				assert.Equal(t, tt.row.Fields, row)
+			}
+			if tt.mutation == nil {
+				assert.Nil(t, mutations)
+				return
+			}
+			// map keys can be deleted in any order, so compare the keys deleted separately
+			var deletedKeys []interface{}
+			var expectedKeys []interface{}
+			collect := func(mutations [][]interface{}, keys *[]interface{}) [][]interface{} {
+				var rest [][]interface{}
+				for _, mutation := range mutations {
+					if mutation[0] == "external_ids" && mutation[1] == ovsdb.MutateOperationDelete {
+						*keys = append(*keys, mutation[2].(*ovsdb.OvsSet).GoSet...)
+						continue
+					}
+					rest = append(rest, mutation)
+				}
+				return rest
+			}
+			assert.ElementsMatch(t, collect(tt.mutation, &expectedKeys), collect(mutations, &deletedKeys))
+			assert.ElementsMatch(t, expectedKeys, deletedKeys)
+		})
+	}
+
+	t.Run("ephemeral columns", func(t *testing.T) {
+		var schema ovsdb.DatabaseSchema
+		require.Nil(t, json.Unmarshal(testServerSchema, &schema))
+		cache, err := newTableCache(&schema, defDB)
+		require.Nil(t, err)
+		a := newAPI(cache)
+		bridge := bridgeType{UUID: aUUID0, Name: "br0"}
+		withStatus := bridge
+		withStatus.Status = map[string]string{"state": "up"}
+		withExternalIds := bridge
+		withExternalIds.ExternalIds = map[string]string{"foo": "bar"}
+		withExternalIds.Status = withStatus.Status
+
+		// "status" is ephemeral, which does not prevent changing it
+		ops, err := a.Where(&bridge).UpdateDiff(&bridge, &withStatus)
+		require.Nil(t, err)
+		require.Len(t, ops, 1)
+		assert.Equal(t, []interface{}{[]interface{}{"status", ovsdb.MutateOperationInsert, testOvsMap(t, withStatus.Status)}}, ops[0].Mutations)
+
+		// unless the old model comes from a snapshot, which does not have its value
+		cache.unreconciled = map[string]bool{"Bridge": true}
+		ops, err = a.Where(&bridge).UpdateDiff(&bridge, &withStatus)
+		require.Nil(t, err)
+		assert.Empty(t, ops)
+		ops, err = a.Where(&bridge).UpdateDiff(&bridge, &withExternalIds)
+		require.Nil(t, err)
+		require.Len(t, ops, 1)
+		assert.Equal(t, []interface{}{[]interface{}{"external_ids", ovsdb.MutateOperationInsert, testOvsMap(t, withExternalIds.ExternalIds)}}, ops[0].Mutations)
+		ops, err = a.Where(&bridge).UpdateDiff(&withStatus, &bridge)
+		require.Nil(t, err)
+		assert.Len(t, ops, 1, "the value of the old model is known")
+	})
+}
+
+func TestAPIUpdateDiffErrors(t *testing.T) {
+	cache := apiTestCache(t)
+	a := newAPI(cache)
+	lsp := testLogicalSwitchPort{UUID: aUUID0}
+	ls := testLogicalSwitch{UUID: aUUID0}
+
+	_, err := a.Where(&lsp).UpdateDiff(&ls, &lsp)
+	assert.IsType(t, &InputTypeError{}, err)
+
+	_, err = a.Where(&lsp).UpdateDiff(lsp, &lsp)
+	assert.IsType(t, &InputTypeError{}, err)
+
+	_, err = a.Where(&testLogicalSwitchPort{}).UpdateDiff(&lsp, &lsp)
+	assert.NotNil(t, err)
+}
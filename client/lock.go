@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// lockReply is the reply to a lock or steal request
+type lockReply struct {
+	Locked bool `json:"locked"`
+}
+
+// Lock requests the lock with the given id and returns whether it has been acquired.
+// If it has not, the request is queued by the server and the registered handlers
+// receive a Locked notification once the lock is acquired.
+// Lock requests are re-issued after a reconnection and handlers receive a Stolen
+// notification for every lock held when the connection is lost, as the server releases them
+// RFC 7047 : lock
+func (ovs *OvsdbClient) Lock(ctx context.Context, id string) (bool, error) {
+	locked, err := ovs.lock(ctx, "lock", id)
+	if err != nil {
+		return false, err
+	}
+	ovs.locksMutex.Lock()
+	defer ovs.locksMutex.Unlock()
+	// a locked notification could have been processed already
+	if _, ok := ovs.locks[id]; !ok || locked {
+		ovs.locks[id] = locked
+	}
+	return locked, nil
+}
+
+// Steal acquires the lock with the given id, taking it from its current owner if needed.
+// The previous owner receives a Stolen notification
+// RFC 7047 : steal
+func (ovs *OvsdbClient) Steal(ctx context.Context, id string) error {
+	locked, err := ovs.lock(ctx, "steal", id)
+	if err != nil {
+		return err
+	}
+	if !locked {
+		return fmt.Errorf("failed to steal lock %s", id)
+	}
+	ovs.locksMutex.Lock()
+	defer ovs.locksMutex.Unlock()
+	ovs.locks[id] = true
+	return nil
+}
+
+// Unlock releases the lock with the given id or cancels a pending request for it
+// RFC 7047 : unlock
+func (ovs *OvsdbClient) Unlock(ctx context.Context, id string) error {
+	var reply map[string]interface{}
+	if err := ovs.call(ctx, "unlock", ovsdb.NewLockArgs(id), &reply); err != nil {
+		return err
+	}
+	ovs.locksMutex.Lock()
+	defer ovs.locksMutex.Unlock()
+	delete(ovs.locks, id)
+	return nil
+}
+
+// lock sends a lock or steal request and returns whether the lock has been acquired
+func (ovs *OvsdbClient) lock(ctx context.Context, method, id string) (bool, error) {
+	var reply lockReply
+	if err := ovs.call(ctx, method, ovsdb.NewLockArgs(id), &reply); err != nil {
+		return false, err
+	}
+	return reply.Locked, nil
+}
+
+// RFC 7047 : Section 4.1.9 : Locked
+func (ovs *OvsdbClient) locked(params []interface{}) error {
+	return ovs.lockNotification(params, true)
+}
+
+// RFC 7047 : Section 4.1.10 : Stolen
+func (ovs *OvsdbClient) stolen(params []interface{}) error {
+	return ovs.lockNotification(params, false)
+}
+
+// lockNotification records the state of the lock in a locked or stolen notification
+// and dispatches the notification to the handlers
+func (ovs *OvsdbClient) lockNotification(params []interface{}, locked bool) error {
+	if len(params) < 1 {
+		return fmt.Errorf("invalid lock notification")
+	}
+	id, ok := params[0].(string)
+	if !ok {
+		return fmt.Errorf("invalid lock notification")
+	}
+	ovs.locksMutex.Lock()
+	ovs.locks[id] = locked
+	ovs.locksMutex.Unlock()
+	ovs.dispatchLock(params, locked)
+	return nil
+}
+
+// dispatchLock sends a Locked or Stolen notification to the handlers
+func (ovs *OvsdbClient) dispatchLock(params []interface{}, locked bool) {
+	ovs.dispatch(func() {
+		for _, handler := range ovs.handlers {
+			if locked {
+				handler.Locked(params)
+			} else {
+				handler.Stolen(params)
+			}
+		}
+	})
+}
+
+// releaseLocks marks every lock as not held, as the server releases them when the
+// connection is lost, and sends a Stolen notification for the ones that were held
+func (ovs *OvsdbClient) releaseLocks() {
+	var released []string
+	ovs.locksMutex.Lock()
+	for id, locked := range ovs.locks {
+		if locked {
+			released = append(released, id)
+			ovs.locks[id] = false
+		}
+	}
+	ovs.locksMutex.Unlock()
+	for _, id := range released {
+		ovs.dispatchLock([]interface{}{id}, false)
+	}
+}
+
+// relock re-issues the requests of every lock after a reconnection. Handlers receive
+// a Locked notification for the locks that are acquired immediately
+func (ovs *OvsdbClient) relock(ctx context.Context) error {
+	ovs.locksMutex.Lock()
+	ids := make([]string, 0, len(ovs.locks))
+	for id := range ovs.locks {
+		ids = append(ids, id)
+	}
+	ovs.locksMutex.Unlock()
+	for _, id := range ids {
+		locked, err := ovs.lock(ctx, "lock", id)
+		if err != nil {
+			return err
+		}
+		if !locked {
+			continue
+		}
+		ovs.locksMutex.Lock()
+		ovs.locks[id] = true
+		ovs.locksMutex.Unlock()
+		ovs.dispatchLock([]interface{}{id}, true)
+	}
+	return nil
+}
@@ -23,10 +23,25 @@ import (
 //}
 type Model interface{}
 
+// ColumnKey addresses a column of a table and, optionally, a key of a map column
+type ColumnKey struct {
+	Column string
+	// Key of the map column whose value is used instead of the whole map
+	Key interface{}
+}
+
+// ClientIndex is an index on columns of a table that the cache maintains in addition to the
+// indexes of the schema. Unlike those, it does not need to be unique. E.g:
+// ClientIndex{Columns: []ColumnKey{{Column: "external_ids", Key: "neutron:id"}}}
+type ClientIndex struct {
+	Columns []ColumnKey
+}
+
 // DBModel is a Database model
 type DBModel struct {
-	name  string
-	types map[string]reflect.Type
+	name    string
+	types   map[string]reflect.Type
+	indexes map[string][]ClientIndex
 }
 
 // newModel returns a new instance of a model from a specific string
@@ -47,6 +62,17 @@ func (db DBModel) Types() map[string]reflect.Type {
 	return db.types
 }
 
+// SetIndexes sets the client indexes of the tables, indexed by table name
+// It must be called before the DBModel is used to create a client
+func (db *DBModel) SetIndexes(indexes map[string][]ClientIndex) {
+	db.indexes = indexes
+}
+
+// Indexes returns the client indexes of a table
+func (db DBModel) Indexes(table string) []ClientIndex {
+	return db.indexes[table]
+}
+
 // Name returns the database name
 func (db DBModel) Name() string {
 	return db.name
@@ -86,6 +112,29 @@ func (db DBModel) Validate(schema *ovsdb.DatabaseSchema) []error {
 			errors = append(errors, err)
 		}
 	}
+
+	for tableName, indexes := range db.indexes {
+		tableSchema := schema.Table(tableName)
+		if _, ok := db.types[tableName]; !ok || tableSchema == nil {
+			errors = append(errors, fmt.Errorf("database model contains client indexes for table %s that is not in the model", tableName))
+			continue
+		}
+		for _, index := range indexes {
+			if len(index.Columns) == 0 {
+				errors = append(errors, fmt.Errorf("client index of table %s has no columns", tableName))
+			}
+			for _, column := range index.Columns {
+				columnSchema := tableSchema.Column(column.Column)
+				if columnSchema == nil {
+					errors = append(errors, fmt.Errorf("client index of table %s contains column %s that does not exist in schema", tableName, column.Column))
+					continue
+				}
+				if column.Key != nil && columnSchema.Type != ovsdb.TypeMap {
+					errors = append(errors, fmt.Errorf("client index of table %s uses a key of column %s that is not a map", tableName, column.Column))
+				}
+			}
+		}
+	}
 	return errors
 }
 
@@ -334,3 +334,57 @@ func TestValidate(t *testing.T) {
 	}
 
 }
+
+func TestValidateClientIndexes(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	err := json.Unmarshal(testServerSchema, &schema)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name    string
+		indexes map[string][]ClientIndex
+		err     bool
+	}{
+		{
+			name:    "map key",
+			indexes: map[string][]ClientIndex{"Bridge": {{Columns: []ColumnKey{{Column: "external_ids", Key: "foo"}}}}},
+		},
+		{
+			name:    "several columns",
+			indexes: map[string][]ClientIndex{"Bridge": {{Columns: []ColumnKey{{Column: "name"}, {Column: "ports"}}}}},
+		},
+		{
+			name:    "unknown table",
+			indexes: map[string][]ClientIndex{"Port": {{Columns: []ColumnKey{{Column: "name"}}}}},
+			err:     true,
+		},
+		{
+			name:    "unknown column",
+			indexes: map[string][]ClientIndex{"Bridge": {{Columns: []ColumnKey{{Column: "foo"}}}}},
+			err:     true,
+		},
+		{
+			name:    "key of a column that is not a map",
+			indexes: map[string][]ClientIndex{"Bridge": {{Columns: []ColumnKey{{Column: "name", Key: "foo"}}}}},
+			err:     true,
+		},
+		{
+			name:    "no columns",
+			indexes: map[string][]ClientIndex{"Bridge": {{}}},
+			err:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := NewDBModel("Open_vSwitch", map[string]Model{"Open_vSwitch": &ovsType{}, "Bridge": &bridgeType{}})
+			assert.Nil(t, err)
+			db.SetIndexes(tt.indexes)
+			errs := db.Validate(&schema)
+			if tt.err {
+				assert.NotEmpty(t, errs)
+			} else {
+				assert.Empty(t, errs)
+			}
+		})
+	}
+}
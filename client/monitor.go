@@ -0,0 +1,297 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+const (
+	monitorRPC           = "monitor"
+	monitorCondRPC       = "monitor_cond"
+	monitorCondChangeRPC = "monitor_cond_change"
+	monitorCondSinceRPC  = "monitor_cond_since"
+)
+
+// zeroTransactionID is sent in monitor_cond_since requests when no transaction id is known
+const zeroTransactionID = "00000000-0000-0000-0000-000000000000"
+
+// TableMonitor describes what to monitor of a table in a conditional monitor
+type TableMonitor struct {
+	// Model is a pointer to a Model of the table to monitor
+	Model Model
+	// Fields are pointers to the fields of Model whose columns shall be monitored
+	// If no field is given, every column in the schema is monitored
+	Fields []interface{}
+	// Conditions restrict the rows that are monitored to the ones that match any of them
+	// The Value of each Condition is used, the content of Model is ignored
+	// If no condition is given, every row is monitored
+	Conditions []Condition
+}
+
+// MonitorCond will provide updates for the rows that match the conditions of the
+// given tables and populate the cache with them. Subsequent updates will be processed
+// by the Update2 Notifications
+// If the client is not connected yet, the monitor is issued by Connect
+// ovsdb-server(7) : monitor_cond
+func (ovs *OvsdbClient) MonitorCond(ctx context.Context, jsonContext interface{}, monitors ...TableMonitor) error {
+	key, err := monitorKey(jsonContext)
+	if err != nil {
+		return err
+	}
+	if len(monitors) == 0 {
+		return fmt.Errorf("at least one table must be monitored")
+	}
+	if ovs.addPendingMonitor(key, &monitor{method: monitorCondRPC, jsonContext: jsonContext, tables: monitors}) {
+		return nil
+	}
+	requests, err := ovs.newMonitorRequests(monitors)
+	if err != nil {
+		return err
+	}
+	reply, err := ovs.monitorCond(ctx, jsonContext, requests)
+	if err != nil {
+		return err
+	}
+	ovs.monitorsMutex.Lock()
+	ovs.monitors[key] = &monitor{
+		method:      monitorCondRPC,
+		jsonContext: jsonContext,
+		requests:    requests,
+	}
+	ovs.monitorsMutex.Unlock()
+	ovs.Cache.populateInitial(monitoredTables(requests), initialTableUpdates(reply))
+	ovs.setLoaded(key)
+	return nil
+}
+
+// MonitorCondSince works like MonitorCond but, if the cache already contains the changes up
+// to a transaction that the server still remembers, only the changes made since then are
+// requested. Otherwise, the contents of the monitored tables are reconciled with the cache.
+// Subsequent updates are processed by the Update3 Notifications, which keep the id of the last
+// transaction available via TableCache.LastTransactionID(). After a reconnection, the monitor
+// is re-issued in the same way so that only the changes missed while disconnected are transferred
+// ovsdb-server(7) : monitor_cond_since
+func (ovs *OvsdbClient) MonitorCondSince(ctx context.Context, jsonContext interface{}, monitors ...TableMonitor) error {
+	key, err := monitorKey(jsonContext)
+	if err != nil {
+		return err
+	}
+	if len(monitors) == 0 {
+		return fmt.Errorf("at least one table must be monitored")
+	}
+	if ovs.addPendingMonitor(key, &monitor{method: monitorCondSinceRPC, jsonContext: jsonContext, tables: monitors}) {
+		return nil
+	}
+	requests, err := ovs.newMonitorRequests(monitors)
+	if err != nil {
+		return err
+	}
+	found, lastTxnID, reply, err := ovs.monitorCondSince(ctx, jsonContext, requests, ovs.Cache.LastTransactionID())
+	if err != nil {
+		return err
+	}
+	ovs.monitorsMutex.Lock()
+	ovs.monitors[key] = &monitor{
+		method:      monitorCondSinceRPC,
+		jsonContext: jsonContext,
+		requests:    requests,
+	}
+	ovs.monitorsMutex.Unlock()
+	tables := monitoredTables(requests)
+	if found {
+		ovs.Cache.update3(lastTxnID, reply)
+		ovs.Cache.setReconciled(tables)
+		ovs.setLoaded(key)
+		return nil
+	}
+	ovs.Cache.reconcile(tables, initialTableUpdates(reply))
+	ovs.Cache.setLastTransactionID(lastTxnID)
+	ovs.setLoaded(key)
+	return nil
+}
+
+// MonitorCondChange replaces the conditions of the given tables in a monitor previously
+// issued with MonitorCond or MonitorCondSince. The Fields of the TableMonitors are ignored
+// as the columns of a monitor cannot be changed. The server sends an update notification
+// that removes from the cache the rows that no longer match the conditions and adds the
+// ones that now do
+// ovsdb-server(7) : monitor_cond_change
+func (ovs *OvsdbClient) MonitorCondChange(ctx context.Context, jsonContext interface{}, monitors ...TableMonitor) error {
+	key, err := monitorKey(jsonContext)
+	if err != nil {
+		return err
+	}
+	ovs.monitorsMutex.Lock()
+	m, ok := ovs.monitors[key]
+	ovs.monitorsMutex.Unlock()
+	if !ok || m.method == monitorRPC {
+		return fmt.Errorf("no conditional monitor with json-value %s", key)
+	}
+
+	changes := make(map[string][]ovsdb.MonitorCondChangeRequest, len(monitors))
+	for _, tm := range monitors {
+		table, request, err := ovs.newMonitorRequest(tm)
+		if err != nil {
+			return err
+		}
+		if _, ok := m.requests[table]; !ok {
+			return fmt.Errorf("table %s is not monitored by monitor %s", table, key)
+		}
+		changes[table] = []ovsdb.MonitorCondChangeRequest{{Where: request.Where}}
+	}
+
+	args := ovsdb.NewMonitorCondChangeArgs(jsonContext, jsonContext, changes)
+	var reply interface{}
+	if err := ovs.call(ctx, monitorCondChangeRPC, args, &reply); err != nil {
+		return err
+	}
+
+	// Keep the conditions up to date so that they are used if the monitor is re-issued
+	ovs.monitorsMutex.Lock()
+	defer ovs.monitorsMutex.Unlock()
+	requests := make(map[string]ovsdb.MonitorRequest, len(m.requests))
+	for table, request := range m.requests {
+		if change, ok := changes[table]; ok {
+			request.Where = change[0].Where
+		}
+		requests[table] = request
+	}
+	current, ok := ovs.monitors[key]
+	if !ok {
+		// the monitor was cancelled meanwhile
+		return nil
+	}
+	// the rest of the state of the monitor, such as whether it has synced, is kept
+	updated := *current
+	updated.requests = requests
+	ovs.monitors[key] = &updated
+	return nil
+}
+
+// monitorCond sends a monitor_cond request and returns the initial contents of the monitored rows
+func (ovs *OvsdbClient) monitorCond(ctx context.Context, jsonContext interface{}, requests map[string]ovsdb.MonitorRequest) (ovsdb.TableUpdates2, error) {
+	args := ovsdb.NewMonitorArgs(ovs.schema().Name, jsonContext, requests)
+	var response map[string]map[string]ovsdb.RowUpdate2
+	err := ovs.call(ctx, monitorCondRPC, args, &response)
+	if err != nil {
+		return ovsdb.TableUpdates2{}, err
+	}
+	return getTableUpdates2FromRawUnmarshal(response), nil
+}
+
+// monitorCondSince sends a monitor_cond_since request. It returns whether the server could
+// provide the changes since lastTxnID, the id of the last transaction in the server and either
+// the changes or, if they could not be provided, the contents of the monitored rows
+func (ovs *OvsdbClient) monitorCondSince(ctx context.Context, jsonContext interface{}, requests map[string]ovsdb.MonitorRequest, lastTxnID string) (bool, string, ovsdb.TableUpdates2, error) {
+	if lastTxnID == "" {
+		lastTxnID = zeroTransactionID
+	}
+	args := ovsdb.NewMonitorCondSinceArgs(ovs.schema().Name, jsonContext, requests, lastTxnID)
+	var response []json.RawMessage
+	err := ovs.call(ctx, monitorCondSinceRPC, args, &response)
+	if err != nil {
+		return false, "", ovsdb.TableUpdates2{}, err
+	}
+	if len(response) != 3 {
+		return false, "", ovsdb.TableUpdates2{}, fmt.Errorf("invalid monitor_cond_since reply: expected 3 elements, got %d", len(response))
+	}
+	var found bool
+	var txnID string
+	var rowUpdates map[string]map[string]ovsdb.RowUpdate2
+	if err := json.Unmarshal(response[0], &found); err != nil {
+		return false, "", ovsdb.TableUpdates2{}, fmt.Errorf("invalid monitor_cond_since reply: %v", err)
+	}
+	if err := json.Unmarshal(response[1], &txnID); err != nil {
+		return false, "", ovsdb.TableUpdates2{}, fmt.Errorf("invalid monitor_cond_since reply: %v", err)
+	}
+	if err := json.Unmarshal(response[2], &rowUpdates); err != nil {
+		return false, "", ovsdb.TableUpdates2{}, fmt.Errorf("invalid monitor_cond_since reply: %v", err)
+	}
+	return found, txnID, getTableUpdates2FromRawUnmarshal(rowUpdates), nil
+}
+
+// newMonitorRequests returns the MonitorRequests, indexed by table, that correspond to the TableMonitors
+func (ovs *OvsdbClient) newMonitorRequests(monitors []TableMonitor) (map[string]ovsdb.MonitorRequest, error) {
+	if len(monitors) == 0 {
+		return nil, fmt.Errorf("at least one table must be monitored")
+	}
+	requests := make(map[string]ovsdb.MonitorRequest, len(monitors))
+	for _, m := range monitors {
+		table, request, err := ovs.newMonitorRequest(m)
+		if err != nil {
+			return nil, err
+		}
+		requests[table] = request
+	}
+	return requests, nil
+}
+
+// newMonitorRequest returns the table name and the MonitorRequest that correspond to a TableMonitor
+func (ovs *OvsdbClient) newMonitorRequest(m TableMonitor) (string, ovsdb.MonitorRequest, error) {
+	var request ovsdb.MonitorRequest
+	table := ovs.dbModel.FindTable(reflect.TypeOf(m.Model))
+	if table == "" {
+		return "", request, fmt.Errorf("model %T does not belong to the database model", m.Model)
+	}
+	tableSchema := ovs.Cache.orm.schema.Table(table)
+	if tableSchema == nil {
+		return "", request, NewErrNoTable(table)
+	}
+
+	if len(m.Fields) > 0 {
+		info, err := newORMInfo(tableSchema, m.Model)
+		if err != nil {
+			return "", request, err
+		}
+		for _, field := range m.Fields {
+			column, err := info.columnByPtr(field)
+			if err != nil {
+				return "", request, err
+			}
+			request.Columns = append(request.Columns, column)
+		}
+	} else {
+		for column := range tableSchema.Columns {
+			request.Columns = append(request.Columns, column)
+		}
+	}
+
+	for _, condition := range m.Conditions {
+		ovsdbCond, err := ovs.Cache.orm.newCondition(table, m.Model, condition)
+		if err != nil {
+			return "", request, err
+		}
+		request.Where = append(request.Where, *ovsdbCond)
+	}
+	request.Select = ovsdb.NewDefaultMonitorSelect()
+	return table, request, nil
+}
+
+// monitoredTables returns the set of tables of the monitor requests
+func monitoredTables(requests map[string]ovsdb.MonitorRequest) map[string]bool {
+	tables := make(map[string]bool, len(requests))
+	for table := range requests {
+		tables[table] = true
+	}
+	return tables
+}
+
+// initialTableUpdates converts the reply to a monitor_cond request into the
+// format of the reply to a monitor request
+func initialTableUpdates(tableUpdates ovsdb.TableUpdates2) ovsdb.TableUpdates {
+	result := ovsdb.TableUpdates{Updates: make(map[string]ovsdb.TableUpdate)}
+	for table, update := range tableUpdates.Updates {
+		rows := make(map[string]ovsdb.RowUpdate, len(update.Rows))
+		for uuid, row := range update.Rows {
+			if row.Initial != nil {
+				rows[uuid] = ovsdb.RowUpdate{New: *row.Initial}
+			}
+		}
+		result.Updates[table] = ovsdb.TableUpdate{Rows: rows}
+	}
+	return result
+}
@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// Comment returns the operation that adds a comment to the transaction, which the server logs
+func (a api) Comment(comment string) ([]ovsdb.Operation, error) {
+	if comment == "" {
+		return nil, fmt.Errorf("comment must not be empty")
+	}
+	return []ovsdb.Operation{{Op: opComment, Comment: comment}}, nil
+}
+
+// Assert returns the operation that makes the transaction fail with ovsdb.NotOwner
+// if the client does not own the lock with the given id
+func (a api) Assert(lock string) ([]ovsdb.Operation, error) {
+	if lock == "" {
+		return nil, fmt.Errorf("lock must not be empty")
+	}
+	return []ovsdb.Operation{{Op: opAssert, Lock: lock}}, nil
+}
+
+// Abort returns the operation that makes the transaction fail with ovsdb.Aborted
+func (a api) Abort() ([]ovsdb.Operation, error) {
+	return []ovsdb.Operation{{Op: opAbort}}, nil
+}
+
+// Wait returns the operations that make the transaction wait, up to timeout milliseconds,
+// until the rows selected by the condition are equal (or not) to a single row with the values
+// of the model. The columns compared are the ones of the fields given (pointers to fields in
+// the model) or, if none is given, the ones of the non-default fields of the model, as Update
+// does. A timeout of 0 makes the transaction fail right away with ovsdb.TimedOut if the condition
+// does not hold
+func (a api) Wait(until ovsdb.WaitCondition, timeout int, model Model, fields ...interface{}) ([]ovsdb.Operation, error) {
+	if until != ovsdb.WaitConditionEqual && until != ovsdb.WaitConditionNotEqual {
+		return nil, fmt.Errorf("invalid wait condition %s", until)
+	}
+	if timeout < 0 {
+		return nil, fmt.Errorf("wait timeout must not be negative")
+	}
+	table, err := a.getTableFromModel(model)
+	if err != nil {
+		return nil, err
+	}
+	conditions, err := a.cond.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := a.cache.orm.newRow(table, model, fields...)
+	if err != nil {
+		return nil, err
+	}
+	if len(row) == 0 {
+		return nil, fmt.Errorf("no column to wait for in table %s", table)
+	}
+	columns := make([]string, 0, len(row))
+	for column := range row {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	var operations []ovsdb.Operation
+	for _, condition := range conditions {
+		operations = append(operations, ovsdb.Operation{
+			Op:      opWait,
+			Table:   table,
+			Where:   condition,
+			Columns: columns,
+			Until:   string(until),
+			Rows:    []map[string]interface{}{row},
+			Timeout: timeout,
+		})
+	}
+	return operations, nil
+}
+
+// Count returns the number of rows that match the condition. A single list of conditions
+// is counted with a mutate operation without mutations, which returns no row. If the
+// Conditional generates several lists, as Where can, the "_uuid" of the rows that match
+// each of them is selected instead, so that rows that match more than one are counted once
+func (a api) Count(ctx context.Context) (int, error) {
+	if a.transact == nil {
+		return 0, fmt.Errorf("count requires a client to run the operation on the server")
+	}
+	conditions, err := a.cond.Generate()
+	if err != nil {
+		return 0, err
+	}
+	if len(conditions) == 0 {
+		return 0, nil
+	}
+	operations := make([]ovsdb.Operation, 0, len(conditions))
+	for _, condition := range conditions {
+		operation := ovsdb.Operation{
+			Op:    opMutate,
+			Table: a.cond.Table(),
+			Where: condition,
+		}
+		if len(conditions) > 1 {
+			operation.Op = opSelect
+			operation.Columns = []string{"_uuid"}
+		}
+		operations = append(operations, operation)
+	}
+
+	reply, err := a.transact(ctx, operations...)
+	if err != nil {
+		return 0, err
+	}
+	if opErrs, err := ovsdb.CheckOperationResults(reply, operations); err != nil {
+		return 0, &TransactionError{Errors: opErrs, err: err}
+	}
+	if len(operations) == 1 {
+		return reply[0].Count, nil
+	}
+	seen := make(map[string]bool)
+	for _, result := range reply[:len(operations)] {
+		for _, row := range result.Rows {
+			uuid, ok := row["_uuid"].(ovsdb.UUID)
+			if !ok {
+				return 0, fmt.Errorf("selected row without _uuid")
+			}
+			seen[uuid.GoUUID] = true
+		}
+	}
+	return len(seen), nil
+}
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPITablelessOperations(t *testing.T) {
+	a := newAPI(apiTestCache(t))
+
+	ops, err := a.Comment("hello")
+	require.Nil(t, err)
+	assert.Equal(t, []ovsdb.Operation{{Op: opComment, Comment: "hello"}}, ops)
+	_, err = a.Comment("")
+	assert.NotNil(t, err)
+
+	ops, err = a.Assert("lock")
+	require.Nil(t, err)
+	assert.Equal(t, []ovsdb.Operation{{Op: opAssert, Lock: "lock"}}, ops)
+	_, err = a.Assert("")
+	assert.NotNil(t, err)
+
+	ops, err = a.Abort()
+	require.Nil(t, err)
+	assert.Equal(t, []ovsdb.Operation{{Op: opAbort}}, ops)
+}
+
+func TestAPIWait(t *testing.T) {
+	a := newAPI(apiTestCache(t))
+	where := []ovsdb.Condition{ovsdb.NewCondition("name", ovsdb.ConditionEqual, "lsp0")}
+	lsp := testLogicalSwitchPort{Name: "lsp0", Type: "someType"}
+
+	tests := []struct {
+		name     string
+		until    ovsdb.WaitCondition
+		timeout  int
+		fields   []interface{}
+		expected []ovsdb.Operation
+		err      bool
+	}{
+		{
+			name:  "non-default fields",
+			until: ovsdb.WaitConditionEqual,
+			expected: []ovsdb.Operation{{
+				Op:      opWait,
+				Table:   "Logical_Switch_Port",
+				Where:   where,
+				Columns: []string{"name", "type"},
+				Until:   "==",
+				Rows:    []map[string]interface{}{{"name": "lsp0", "type": "someType"}},
+			}},
+		},
+		{
+			name:    "fields",
+			until:   ovsdb.WaitConditionNotEqual,
+			timeout: 1000,
+			fields:  []interface{}{&lsp.Type, &lsp.ExternalIds},
+			expected: []ovsdb.Operation{{
+				Op:      opWait,
+				Table:   "Logical_Switch_Port",
+				Where:   where,
+				Columns: []string{"external_ids", "type"},
+				Until:   "!=",
+				Rows:    []map[string]interface{}{{"type": "someType", "external_ids": testOvsMap(t, map[string]string{})}},
+				Timeout: 1000,
+			}},
+		},
+		{
+			name:  "invalid condition",
+			until: "<",
+			err:   true,
+		},
+		{
+			name:    "negative timeout",
+			until:   ovsdb.WaitConditionEqual,
+			timeout: -1,
+			err:     true,
+		},
+		{
+			name:   "invalid field",
+			until:  ovsdb.WaitConditionEqual,
+			fields: []interface{}{&where},
+			err:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops, err := a.Where(&lsp).Wait(tt.until, tt.timeout, &lsp, tt.fields...)
+			if tt.err {
+				assert.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.expected, ops)
+		})
+	}
+
+	_, err := a.Where(&lsp).Wait(ovsdb.WaitConditionEqual, 0, &testLogicalSwitchPort{})
+	assert.NotNil(t, err)
+}
+
+func TestAPISelectOperations(t *testing.T) {
+	a := newAPI(apiTestCache(t))
+	lsp := testLogicalSwitchPort{Name: "lsp0"}
+	ops, err := a.Where(&lsp).SelectOperations(&lsp.Type)
+	require.Nil(t, err)
+	assert.Equal(t, []ovsdb.Operation{{
+		Op:      opSelect,
+		Table:   "Logical_Switch_Port",
+		Where:   []ovsdb.Condition{ovsdb.NewCondition("name", ovsdb.ConditionEqual, "lsp0")},
+		Columns: []string{"_uuid", "type"},
+	}}, ops)
+}
+
+func TestAPICount(t *testing.T) {
+	cache := apiTestCache(t)
+	var sent []ovsdb.Operation
+	var reply []ovsdb.OperationResult
+	a := newTransactAPI(cache, func(ctx context.Context, ops ...ovsdb.Operation) ([]ovsdb.OperationResult, error) {
+		sent = ops
+		return reply, nil
+	}, false)
+	model := testLogicalSwitchPort{}
+
+	// a single list of conditions is counted by the server
+	reply = []ovsdb.OperationResult{{Count: 2}}
+	count, err := a.WhereAll(&model,
+		Condition{Field: &model.Type, Function: ovsdb.ConditionEqual, Value: "someType"},
+		Condition{Field: &model.Name, Function: ovsdb.ConditionEqual, Value: "lsp0"},
+	).Count(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, 2, count)
+	require.Len(t, sent, 1)
+	assert.Equal(t, opMutate, sent[0].Op)
+	assert.Equal(t, "Logical_Switch_Port", sent[0].Table)
+	assert.Empty(t, sent[0].Mutations)
+
+	// rows that match several lists of conditions are counted once
+	reply = []ovsdb.OperationResult{
+		{Rows: []ovsdb.ResultRow{{"_uuid": ovsdb.UUID{GoUUID: aUUID0}}, {"_uuid": ovsdb.UUID{GoUUID: aUUID1}}}},
+		{Rows: []ovsdb.ResultRow{{"_uuid": ovsdb.UUID{GoUUID: aUUID1}}}},
+	}
+	count, err = a.Where(&model,
+		Condition{Field: &model.Type, Function: ovsdb.ConditionEqual, Value: "someType"},
+		Condition{Field: &model.Name, Function: ovsdb.ConditionEqual, Value: "lsp0"},
+	).Count(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, 2, count)
+	require.Len(t, sent, 2)
+	for _, op := range sent {
+		assert.Equal(t, opSelect, op.Op)
+		assert.Equal(t, "Logical_Switch_Port", op.Table)
+		assert.Equal(t, []string{"_uuid"}, op.Columns)
+	}
+
+	reply = []ovsdb.OperationResult{{Error: "constraint violation"}}
+	_, err = a.Where(&testLogicalSwitchPort{Name: "lsp0"}).Count(context.Background())
+	assert.IsType(t, &TransactionError{}, err)
+
+	_, err = newAPI(cache).Where(&testLogicalSwitchPort{Name: "lsp0"}).Count(context.Background())
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,247 @@
+package client
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ConnectionState describes the state of the connection to the OVSDB server
+type ConnectionState int
+
+const (
+	// StateDisconnected means there is no connection to the server and none will be attempted
+	StateDisconnected ConnectionState = iota
+	// StateConnecting means the first connection to the server is being established
+	StateConnecting
+	// StateConnected means the client is connected and its monitors are in place
+	StateConnected
+	// StateReconnecting means the connection was lost and the client is trying to restore it
+	StateReconnecting
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case StateDisconnected:
+		return "disconnected"
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateReconnecting:
+		return "reconnecting"
+	default:
+		return fmt.Sprintf("unknown (%d)", int(s))
+	}
+}
+
+// ConnectionStateHandler is called every time the ConnectionState of the client changes
+type ConnectionStateHandler func(old, new ConnectionState)
+
+// Option is used to configure optional behaviour of the OvsdbClient
+type Option func(o *options) error
+
+type options struct {
+	endpoints []string
+	tlsConfig *tls.Config
+	logger    *log.Logger
+
+	reconnect     bool
+	backoff       Backoff
+	stateHandlers []ConnectionStateHandler
+	leaderOnly    bool
+
+	inactivityInterval time.Duration
+	inactivityTimeout  time.Duration
+
+	eventDelivery   EventDeliveryMode
+	eventBufferSize int
+	cacheResync     time.Duration
+
+	transactionRetries   int
+	skipImmutableColumns bool
+}
+
+func newOptions(opts ...Option) (*options, error) {
+	o := &options{logger: log.Default(), eventBufferSize: bufferSize, transactionRetries: defaultTransactionRetries}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+	if o.leaderOnly && !o.reconnect {
+		o.reconnect = true
+		o.backoff = NewExponentialBackoff()
+	}
+	return o, nil
+}
+
+// WithEndpoint adds the endpoints, in ovsdb Connection Methods format and separated
+// by commas, to the ones the client connects to. Endpoints are tried in order
+func WithEndpoint(endpoint string) Option {
+	return func(o *options) error {
+		for _, e := range strings.Split(endpoint, ",") {
+			if _, err := url.Parse(e); err != nil {
+				return err
+			}
+			o.endpoints = append(o.endpoints, e)
+		}
+		return nil
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used by ssl endpoints
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(o *options) error {
+		o.tlsConfig = tlsConfig
+		return nil
+	}
+}
+
+// WithConfig sets the endpoints and the TLS configuration from a Config
+func WithConfig(config Config) Option {
+	return func(o *options) error {
+		if config.Addr == "" {
+			return fmt.Errorf("config address cannot be empty")
+		}
+		if err := WithEndpoint(config.Addr)(o); err != nil {
+			return err
+		}
+		o.tlsConfig = config.TLSConfig
+		return nil
+	}
+}
+
+// WithLogger sets the logger used by the client. It defaults to log.Default()
+func WithLogger(logger *log.Logger) Option {
+	return func(o *options) error {
+		if logger == nil {
+			return fmt.Errorf("logger cannot be nil")
+		}
+		o.logger = logger
+		return nil
+	}
+}
+
+// WithReconnect makes the client re-establish the connection when it is lost.
+// After reconnecting, the schema is validated again, every active monitor is
+// re-issued and the cache is reconciled with the contents of the server.
+// The time between attempts is given by backoff. If it is nil,
+// NewExponentialBackoff() is used
+func WithReconnect(backoff Backoff) Option {
+	return func(o *options) error {
+		if backoff == nil {
+			backoff = NewExponentialBackoff()
+		}
+		o.reconnect = true
+		o.backoff = backoff
+		return nil
+	}
+}
+
+// WithConnectionStateHandler registers a handler that is called on every
+// ConnectionState transition
+func WithConnectionStateHandler(handler ConnectionStateHandler) Option {
+	return func(o *options) error {
+		if handler == nil {
+			return fmt.Errorf("connection state handler cannot be nil")
+		}
+		o.stateHandlers = append(o.stateHandlers, handler)
+		return nil
+	}
+}
+
+// WithLeaderOnly makes the client only connect to servers that are the leader of
+// their cluster. The status of the database is monitored through the _Server database
+// and the connection is dropped if the server loses the leadership or the connectivity
+// with the rest of the cluster, or if it is not as up to date as a server the client
+// was previously connected to. Endpoints are then tried in order until the leader is found.
+// Servers of standalone databases are always accepted.
+// Reconnection is enabled, with NewExponentialBackoff() unless WithReconnect is also given
+func WithLeaderOnly(leaderOnly bool) Option {
+	return func(o *options) error {
+		o.leaderOnly = leaderOnly
+		return nil
+	}
+}
+
+// WithInactivityCheck makes the client send an echo request to the server when nothing
+// has been received from it for interval. If the reply does not arrive within timeout,
+// the connection is considered dead and closed, which makes the client reconnect if
+// enabled or notify the handlers with Disconnected() otherwise. Nothing is read while
+// notifications are delivered, which may block with EventDeliveryBlock, and that time
+// is not considered inactivity
+func WithInactivityCheck(interval, timeout time.Duration) Option {
+	return func(o *options) error {
+		if interval <= 0 || timeout <= 0 {
+			return fmt.Errorf("inactivity interval and timeout must be positive")
+		}
+		o.inactivityInterval = interval
+		o.inactivityTimeout = timeout
+		return nil
+	}
+}
+
+// WithEventDelivery sets how the events of the cache are delivered to the handlers when they
+// do not keep up with the updates. It defaults to EventDeliveryBlock, see EventDeliveryMode
+func WithEventDelivery(mode EventDeliveryMode) Option {
+	return func(o *options) error {
+		switch mode {
+		case EventDeliveryBlock, EventDeliveryDrop, EventDeliveryCoalesce:
+		default:
+			return fmt.Errorf("unknown event delivery mode %d", mode)
+		}
+		o.eventDelivery = mode
+		return nil
+	}
+}
+
+// WithEventBufferSize sets the number of events of the cache that can be waiting to be
+// delivered to the handlers before the EventDeliveryMode applies. It defaults to 65536
+func WithEventBufferSize(size int) Option {
+	return func(o *options) error {
+		if size <= 0 {
+			return fmt.Errorf("event buffer size must be positive")
+		}
+		o.eventBufferSize = size
+		return nil
+	}
+}
+
+// WithCacheResync makes the cache replay, every period, an update event for each of its rows
+// with the same model as old and new, so that handlers can periodically reconcile their state
+// with the contents of the cache, as informers do
+func WithCacheResync(period time.Duration) Option {
+	return func(o *options) error {
+		if period <= 0 {
+			return fmt.Errorf("cache resync period must be positive")
+		}
+		o.cacheResync = period
+		return nil
+	}
+}
+
+// WithTransactionRetries sets how many times OvsdbClient.RetryTransaction runs a transaction
+// again when it fails because the rows it read changed. By default, it is retried 3 times
+func WithTransactionRetries(retries int) Option {
+	return func(o *options) error {
+		if retries < 0 {
+			return fmt.Errorf("transaction retries must not be negative")
+		}
+		o.transactionRetries = retries
+		return nil
+	}
+}
+
+// WithSkipImmutableColumns makes Update, UpdateDiff and Mutate leave out the columns that
+// are not mutable, which the server refuses to modify, instead of failing with ErrImmutableColumn.
+// Operations that are left with nothing to modify are not generated
+func WithSkipImmutableColumns() Option {
+	return func(o *options) error {
+		o.skipImmutableColumns = true
+		return nil
+	}
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"crypto/tls"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOptions(t *testing.T) {
+	tlsConfig := &tls.Config{}
+	tests := []struct {
+		name      string
+		opts      []Option
+		endpoints []string
+		tlsConfig *tls.Config
+		reconnect bool
+		err       bool
+	}{
+		{
+			name:      "endpoints",
+			opts:      []Option{WithEndpoint("unix:/a.sock,tcp:127.0.0.1:6641"), WithEndpoint("tcp:127.0.0.1:6642")},
+			endpoints: []string{"unix:/a.sock", "tcp:127.0.0.1:6641", "tcp:127.0.0.1:6642"},
+		},
+		{
+			name:      "config",
+			opts:      []Option{WithConfig(Config{Addr: "ssl:127.0.0.1:6641", TLSConfig: tlsConfig})},
+			endpoints: []string{"ssl:127.0.0.1:6641"},
+			tlsConfig: tlsConfig,
+		},
+		{
+			name: "empty config",
+			opts: []Option{WithConfig(Config{})},
+			err:  true,
+		},
+		{
+			name: "invalid endpoint",
+			opts: []Option{WithEndpoint("tcp:127.0.0.1:6641,%zz")},
+			err:  true,
+		},
+		{
+			name: "nil logger",
+			opts: []Option{WithLogger(nil)},
+			err:  true,
+		},
+		{
+			name:      "leader only",
+			opts:      []Option{WithLeaderOnly(true)},
+			reconnect: true,
+		},
+		{
+			name: "unknown event delivery mode",
+			opts: []Option{WithEventDelivery(EventDeliveryMode(42))},
+			err:  true,
+		},
+		{
+			name: "invalid event buffer size",
+			opts: []Option{WithEventBufferSize(0)},
+			err:  true,
+		},
+		{
+			name: "negative transaction retries",
+			opts: []Option{WithTransactionRetries(-1)},
+			err:  true,
+		},
+		{
+			name: "invalid inactivity check",
+			opts: []Option{WithInactivityCheck(time.Second, 0)},
+			err:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := newOptions(tt.opts...)
+			if tt.err {
+				assert.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.endpoints, o.endpoints)
+			assert.Equal(t, tt.tlsConfig, o.tlsConfig)
+			assert.Equal(t, tt.reconnect, o.reconnect)
+			assert.NotNil(t, o.logger)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"log"
 	"os"
 	"testing"
@@ -70,14 +71,14 @@ func TestConnectIntegration(t *testing.T) {
 	go func() {
 		// Use Convenience params. Ignore failure even if any
 
-		_, err := Connect(cfg.Addr, defDB, nil)
+		_, err := Connect(context.Background(), cfg.Addr, defDB, nil)
 		if err != nil {
 			log.Println("Couldnt establish OVSDB connection with Defult params. No big deal")
 		}
 	}()
 
 	go func() {
-		ovs, err := Connect(cfg.Addr, defDB, nil)
+		ovs, err := Connect(context.Background(), cfg.Addr, defDB, nil)
 		if err != nil {
 			connected <- false
 		} else {
@@ -105,11 +106,11 @@ func TestListDbsIntegration(t *testing.T) {
 		t.Skip()
 	}
 
-	ovs, err := Connect(cfg.Addr, defDB, nil)
+	ovs, err := Connect(context.Background(), cfg.Addr, defDB, nil)
 	if err != nil {
 		t.Fatalf("Failed to Connect. error: %s", err)
 	}
-	reply, err := ovs.ListDbs()
+	reply, err := ovs.ListDbs(context.Background())
 
 	if err != nil {
 		log.Fatal("ListDbs error:", err)
@@ -140,13 +141,13 @@ func TestGetSchemasIntegration(t *testing.T) {
 		t.Skip()
 	}
 
-	ovs, err := Connect(cfg.Addr, defDB, nil)
+	ovs, err := Connect(context.Background(), cfg.Addr, defDB, nil)
 	if err != nil {
 		t.Fatalf("Failed to Connect. error: %s", err)
 	}
 
 	dbName := "Open_vSwitch"
-	reply, err := ovs.GetSchema(dbName)
+	reply, err := ovs.GetSchema(context.Background(), dbName)
 
 	if err != nil {
 		log.Fatal("GetSchemas error:", err)
@@ -168,11 +169,11 @@ func TestInsertTransactIntegration(t *testing.T) {
 	}
 	SetConfig()
 
-	ovs, err := Connect(cfg.Addr, defDB, nil)
+	ovs, err := Connect(context.Background(), cfg.Addr, defDB, nil)
 	if err != nil {
 		t.Fatalf("Failed to Connect. error: %s", err)
 	}
-	err = ovs.MonitorAll(nil)
+	err = ovs.MonitorAll(context.Background(), nil)
 	assert.Nil(t, err)
 
 	// NamedUUID is used to add multiple related Operations in a single Transact operation
@@ -202,7 +203,7 @@ func TestInsertTransactIntegration(t *testing.T) {
 	assert.Nil(t, err)
 
 	operations := append(insertOp, mutateOp...)
-	reply, err := ovs.Transact(operations...)
+	reply, err := ovs.Transact(context.Background(), operations...)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -228,11 +229,11 @@ func TestDeleteTransactIntegration(t *testing.T) {
 		t.Skip()
 	}
 
-	ovs, err := Connect(cfg.Addr, defDB, nil)
+	ovs, err := Connect(context.Background(), cfg.Addr, defDB, nil)
 	if err != nil {
 		t.Fatalf("Failed to Connect. error: %s", err)
 	}
-	err = ovs.MonitorAll(nil)
+	err = ovs.MonitorAll(context.Background(), nil)
 	assert.Nil(t, err)
 
 	deleteOp, err := ovs.Where(&bridgeType{Name: bridgeName}).Delete()
@@ -251,7 +252,7 @@ func TestDeleteTransactIntegration(t *testing.T) {
 	assert.Nil(t, err)
 
 	operations := append(deleteOp, mutateOp...)
-	reply, err := ovs.Transact(operations...)
+	reply, err := ovs.Transact(context.Background(), operations...)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -275,12 +276,12 @@ func TestMonitorIntegration(t *testing.T) {
 		t.Skip()
 	}
 
-	ovs, err := Connect(cfg.Addr, defDB, nil)
+	ovs, err := Connect(context.Background(), cfg.Addr, defDB, nil)
 	if err != nil {
 		t.Fatalf("Failed to Connect. error: %s", err)
 	}
 
-	err = ovs.MonitorAll(nil)
+	err = ovs.MonitorAll(context.Background(), nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -293,7 +294,7 @@ func TestNotifyIntegration(t *testing.T) {
 	}
 	SetConfig()
 
-	ovs, err := Connect(cfg.Addr, defDB, nil)
+	ovs, err := Connect(context.Background(), cfg.Addr, defDB, nil)
 	if err != nil {
 		t.Fatalf("Failed to Connect. error: %s", err)
 	}
@@ -324,7 +325,7 @@ func TestRemoveNotifyIntegration(t *testing.T) {
 	}
 	SetConfig()
 
-	ovs, err := Connect(cfg.Addr, defDB, nil)
+	ovs, err := Connect(context.Background(), cfg.Addr, defDB, nil)
 	if err != nil {
 		t.Fatalf("Failed to Connect. error: %s", err)
 	}
@@ -351,6 +352,8 @@ type Notifier struct {
 
 func (n Notifier) Update(interface{}, ovsdb.TableUpdates) {
 }
+func (n Notifier) Update2(interface{}, ovsdb.TableUpdates2) {
+}
 func (n Notifier) Locked([]interface{}) {
 }
 func (n Notifier) Stolen([]interface{}) {
@@ -367,7 +370,7 @@ func TestTableSchemaValidationIntegration(t *testing.T) {
 	}
 	SetConfig()
 
-	ovs, err := Connect(cfg.Addr, defDB, nil)
+	ovs, err := Connect(context.Background(), cfg.Addr, defDB, nil)
 	if err != nil {
 		t.Fatalf("Failed to Connect. error: %s", err)
 	}
@@ -380,7 +383,7 @@ func TestTableSchemaValidationIntegration(t *testing.T) {
 		Table: "InvalidTable",
 		Row:   bridge,
 	}
-	_, err = ovs.Transact(operation)
+	_, err = ovs.Transact(context.Background(), operation)
 
 	if err == nil {
 		t.Error("Invalid Table Name Validation failed")
@@ -395,7 +398,7 @@ func TestColumnSchemaInRowValidationIntegration(t *testing.T) {
 	}
 	SetConfig()
 
-	ovs, err := Connect(cfg.Addr, defDB, nil)
+	ovs, err := Connect(context.Background(), cfg.Addr, defDB, nil)
 	if err != nil {
 		t.Fatalf("Failed to Connect. error: %s", err)
 	}
@@ -410,7 +413,7 @@ func TestColumnSchemaInRowValidationIntegration(t *testing.T) {
 		Row:   bridge,
 	}
 
-	_, err = ovs.Transact(operation)
+	_, err = ovs.Transact(context.Background(), operation)
 
 	if err == nil {
 		t.Error("Invalid Column Name Validation failed")
@@ -425,7 +428,7 @@ func TestColumnSchemaInMultipleRowsValidationIntegration(t *testing.T) {
 	}
 	SetConfig()
 
-	ovs, err := Connect(cfg.Addr, defDB, nil)
+	ovs, err := Connect(context.Background(), cfg.Addr, defDB, nil)
 	if err != nil {
 		t.Fatalf("Failed to Connect. error: %s", err)
 	}
@@ -445,7 +448,7 @@ func TestColumnSchemaInMultipleRowsValidationIntegration(t *testing.T) {
 		Table: "Bridge",
 		Rows:  rows,
 	}
-	_, err = ovs.Transact(operation)
+	_, err = ovs.Transact(context.Background(), operation)
 
 	if err == nil {
 		t.Error("Invalid Column Name Validation failed")
@@ -463,7 +466,7 @@ func TestColumnSchemaValidationIntegration(t *testing.T) {
 		t.Skip()
 	}
 
-	ovs, err := Connect(cfg.Addr, defDB, nil)
+	ovs, err := Connect(context.Background(), cfg.Addr, defDB, nil)
 	if err != nil {
 		t.Fatalf("Failed to Connect. error: %s", err)
 	}
@@ -473,7 +476,7 @@ func TestColumnSchemaValidationIntegration(t *testing.T) {
 		Table:   "Bridge",
 		Columns: []string{"name", "invalidColumn"},
 	}
-	_, err = ovs.Transact(operation)
+	_, err = ovs.Transact(context.Background(), operation)
 
 	if err == nil {
 		t.Error("Invalid Column Name Validation failed")
@@ -488,7 +491,7 @@ func TestMonitorCancelIntegration(t *testing.T) {
 	}
 	SetConfig()
 
-	ovs, err := Connect(cfg.Addr, defDB, nil)
+	ovs, err := Connect(context.Background(), cfg.Addr, defDB, nil)
 	if err != nil {
 		t.Fatalf("Failed to Connect. error: %s", err)
 	}
@@ -501,12 +504,12 @@ func TestMonitorCancelIntegration(t *testing.T) {
 		Select:  ovsdb.NewDefaultMonitorSelect(),
 	}
 
-	err = ovs.Monitor(monitorID, requests)
+	err = ovs.Monitor(context.Background(), monitorID, requests)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	err = ovs.MonitorCancel(monitorID)
+	err = ovs.MonitorCancel(context.Background(), monitorID)
 
 	if err != nil {
 		t.Error("MonitorCancel operation failed with error=", err)
@@ -520,11 +523,11 @@ func TestInsertDuplicateTransactIntegration(t *testing.T) {
 	}
 	SetConfig()
 
-	ovs, err := Connect(cfg.Addr, defDB, nil)
+	ovs, err := Connect(context.Background(), cfg.Addr, defDB, nil)
 	if err != nil {
 		t.Fatalf("Failed to Connect. error: %s", err)
 	}
-	err = ovs.MonitorAll(nil)
+	err = ovs.MonitorAll(context.Background(), nil)
 	assert.Nil(t, err)
 
 	// NamedUUID is used to add multiple related Operations in a single Transact operation
@@ -554,7 +557,7 @@ func TestInsertDuplicateTransactIntegration(t *testing.T) {
 	assert.Nil(t, err)
 
 	operations := append(insertOp, mutateOp...)
-	reply, err := ovs.Transact(operations...)
+	reply, err := ovs.Transact(context.Background(), operations...)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -567,7 +570,7 @@ func TestInsertDuplicateTransactIntegration(t *testing.T) {
 	}
 	bridgeUUID = reply[0].UUID.GoUUID
 
-	reply, err = ovs.Transact(operations...)
+	reply, err = ovs.Transact(context.Background(), operations...)
 	if err != nil {
 		t.Fatal(err)
 	}
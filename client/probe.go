@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/cenkalti/rpc2"
+)
+
+// activityConn is a net.Conn that records the time of the last successful read
+type activityConn struct {
+	net.Conn
+	// lastRead is the time of the last read, or of the end of the last delivery of
+	// notifications, in nanoseconds since the epoch
+	lastRead int64
+	// delivering is the number of deliveries of notifications in progress
+	delivering int32
+}
+
+func newActivityConn(conn net.Conn) *activityConn {
+	return &activityConn{
+		Conn:     conn,
+		lastRead: time.Now().UnixNano(),
+	}
+}
+
+// Read implements the net.Conn interface
+func (c *activityConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		atomic.StoreInt64(&c.lastRead, time.Now().UnixNano())
+	}
+	return n, err
+}
+
+// idle returns the time elapsed since the last read. Nothing is read while notifications
+// are delivered, as rpc2 handles them in its read loop, so the time spent delivering them,
+// e.g. waiting for room in the buffer of events of the cache, does not count
+func (c *activityConn) idle() time.Duration {
+	if atomic.LoadInt32(&c.delivering) > 0 {
+		return 0
+	}
+	return time.Since(time.Unix(0, atomic.LoadInt64(&c.lastRead)))
+}
+
+// activeSince returns whether something was read or notifications were delivered since t
+func (c *activityConn) activeSince(t time.Time) bool {
+	return atomic.LoadInt32(&c.delivering) > 0 || atomic.LoadInt64(&c.lastRead) > t.UnixNano()
+}
+
+// deliver runs the delivery of a notification, which blocks the read loop, so that
+// the time it takes is not considered inactivity. c may be nil
+func (c *activityConn) deliver(f func() error) error {
+	if c == nil {
+		return f()
+	}
+	atomic.AddInt32(&c.delivering, 1)
+	defer func() {
+		atomic.StoreInt64(&c.lastRead, time.Now().UnixNano())
+		atomic.AddInt32(&c.delivering, -1)
+	}()
+	return f()
+}
+
+// inactivityProbe sends an echo request when nothing has been received on the connection
+// for the inactivity interval and closes the connection if the reply does not arrive in time,
+// unless the reply could not be read because notifications were being delivered meanwhile.
+// It returns when the connection is closed
+func (ovs *OvsdbClient) inactivityProbe(rpcClient *rpc2.Client, conn *activityConn) {
+	interval := ovs.options.inactivityInterval
+	wait := interval
+	for {
+		select {
+		case <-rpcClient.DisconnectNotify():
+			return
+		case <-time.After(wait):
+		}
+		if idle := conn.idle(); idle < interval {
+			wait = interval - idle
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), ovs.options.inactivityTimeout)
+		var reply []interface{}
+		sent := time.Now()
+		err := callClient(ctx, rpcClient, "echo", []interface{}{"libovsdb echo"}, &reply)
+		cancel()
+		if err != nil && err == ctx.Err() && conn.activeSince(sent) {
+			wait = interval
+			continue
+		}
+		if err != nil {
+			ovs.options.logger.Printf("closing connection: inactivity probe failed: %v", err)
+			rpcClient.Close()
+			return
+		}
+		wait = interval
+	}
+}
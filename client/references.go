@@ -0,0 +1,209 @@
+package client
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// refTable returns the table referenced by a column, or "" if the column does not
+// hold references. Map columns may reference rows by key, by value or both
+func refTable(column *ovsdb.ColumnSchema) (string, error) {
+	if column.TypeObj == nil {
+		return "", nil
+	}
+	var table string
+	for _, baseType := range []*ovsdb.BaseType{column.TypeObj.Key, column.TypeObj.Value} {
+		if baseType == nil || baseType.RefTable == nil {
+			continue
+		}
+		if table != "" && table != *baseType.RefTable {
+			return "", fmt.Errorf("column references tables %s and %s", table, *baseType.RefTable)
+		}
+		table = *baseType.RefTable
+	}
+	return table, nil
+}
+
+// referencedUUIDs returns the UUIDs held by the native value of a column that references other rows
+func referencedUUIDs(column *ovsdb.ColumnSchema, value interface{}) []string {
+	isRef := func(baseType *ovsdb.BaseType) bool {
+		return baseType != nil && baseType.RefTable != nil
+	}
+	var uuids []string
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.String:
+		if uuid := v.String(); uuid != "" {
+			uuids = append(uuids, uuid)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			uuids = append(uuids, v.Index(i).String())
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if isRef(column.TypeObj.Key) {
+				uuids = append(uuids, iter.Key().String())
+			}
+			if isRef(column.TypeObj.Value) {
+				uuids = append(uuids, iter.Value().String())
+			}
+		}
+	}
+	return uuids
+}
+
+// referencedRows returns the rows referenced by a model of the table
+func referencedRows(table *ovsdb.TableSchema, info *ormInfo) []rowKey {
+	var rows []rowKey
+	for name, column := range table.Columns {
+		ref, err := refTable(column)
+		if err != nil || ref == "" || !info.hasColumn(name) {
+			continue
+		}
+		value, err := info.fieldByColumn(name)
+		if err != nil {
+			continue
+		}
+		for _, uuid := range referencedUUIDs(column, value) {
+			rows = append(rows, rowKey{table: ref, uuid: uuid})
+		}
+	}
+	return rows
+}
+
+// addReferences records the rows referenced by a row of the table
+// The caller must hold the mutex
+func (r *RowCache) addReferences(uuid string, info *ormInfo) {
+	for _, ref := range referencedRows(r.table, info) {
+		if r.references[ref] == nil {
+			r.references[ref] = make(map[string]bool)
+		}
+		r.references[ref][uuid] = true
+	}
+}
+
+// removeReferences forgets the rows referenced by a row of the table
+// The caller must hold the mutex
+func (r *RowCache) removeReferences(uuid string, info *ormInfo) {
+	for _, ref := range referencedRows(r.table, info) {
+		delete(r.references[ref], uuid)
+		if len(r.references[ref]) == 0 {
+			delete(r.references, ref)
+		}
+	}
+}
+
+// referencing returns the UUIDs of the rows of the table that reference the given row
+func (r *RowCache) referencing(table, uuid string) []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	uuids := make([]string, 0, len(r.references[rowKey{table, uuid}]))
+	for referrer := range r.references[rowKey{table, uuid}] {
+		uuids = append(uuids, referrer)
+	}
+	return uuids
+}
+
+// References populates a slice of Models, given as parameter, with the rows of the cache
+// referenced by a field of the model, which must hold UUIDs of a column with a refTable
+func (a api) References(model Model, field interface{}, result interface{}) error {
+	table, err := a.getTableFromModel(model)
+	if err != nil {
+		return err
+	}
+	tableSchema := a.cache.orm.schema.Table(table)
+	info, err := newORMInfo(tableSchema, model)
+	if err != nil {
+		return err
+	}
+	column, err := info.columnByPtr(field)
+	if err != nil {
+		return err
+	}
+	columnSchema := tableSchema.Column(column)
+	ref, err := refTable(columnSchema)
+	if err != nil {
+		return fmt.Errorf("table %s, column %s: %v", table, column, err)
+	}
+	if ref == "" {
+		return fmt.Errorf("table %s, column %s does not reference other rows", table, column)
+	}
+	value, err := info.fieldByColumn(column)
+	if err != nil {
+		return err
+	}
+	return a.appendRows(result, ref, referencedUUIDs(columnSchema, value))
+}
+
+// ReferencedBy populates a slice of Models, given as parameter, with the rows of their table
+// that reference the model. The model is looked up in the cache in the same way as Get does
+func (a api) ReferencedBy(model Model, result interface{}) error {
+	table, err := a.getTableFromModel(model)
+	if err != nil {
+		return err
+	}
+	tableCache := a.cache.Table(table)
+	if tableCache == nil {
+		return ErrNotFound
+	}
+	rows, indexed, err := tableCache.rowsByModel(model, true)
+	if err != nil {
+		return err
+	}
+	if !indexed {
+		info, err := newORMInfo(a.cache.orm.schema.Table(table), model)
+		if err != nil {
+			return err
+		}
+		if uuid, err := info.fieldByColumn("_uuid"); err == nil && tableCache.Row(uuid.(string)) != nil {
+			rows = []string{uuid.(string)}
+		}
+	}
+	if len(rows) == 0 {
+		return ErrNotFound
+	}
+	resultTable, err := a.getTableFromSlice(result)
+	if err != nil {
+		return err
+	}
+	var uuids []string
+	if referrers := a.cache.Table(resultTable); referrers != nil {
+		uuids = referrers.referencing(table, rows[0])
+	}
+	return a.appendRows(result, resultTable, uuids)
+}
+
+// getTableFromSlice returns the table of the models of a pointer to a slice of Models
+func (a api) getTableFromSlice(result interface{}) (string, error) {
+	resultPtr := reflect.ValueOf(result)
+	if resultPtr.Type().Kind() != reflect.Ptr || resultPtr.Elem().Kind() != reflect.Slice {
+		return "", &InputTypeError{resultPtr.Type(), "Expected pointer to slice of valid Models"}
+	}
+	return a.getTableFromModel(reflect.New(resultPtr.Elem().Type().Elem()).Interface())
+}
+
+// appendRows appends the given rows of the table, if cached, to a pointer to a slice of Models
+func (a api) appendRows(result interface{}, table string, uuids []string) error {
+	resultTable, err := a.getTableFromSlice(result)
+	if err != nil {
+		return err
+	}
+	if resultTable != table {
+		return &InputTypeError{reflect.TypeOf(result),
+			fmt.Sprintf("Table derived from input type (%s) does not match referenced table (%s)", resultTable, table)}
+	}
+	resultVal := reflect.ValueOf(result).Elem()
+	tableCache := a.cache.Table(table)
+	if tableCache == nil {
+		return nil
+	}
+	for _, uuid := range uuids {
+		if elem := tableCache.Row(uuid); elem != nil {
+			resultVal.Set(reflect.Append(resultVal, deepCopy(reflect.Indirect(reflect.ValueOf(elem)))))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIReferences(t *testing.T) {
+	cache := apiTestCache(t)
+	lsps := []*testLogicalSwitchPort{
+		{UUID: aUUID0, Name: "lsp0"},
+		{UUID: aUUID1, Name: "lsp1"},
+		{UUID: aUUID2, Name: "lsp2"},
+	}
+	lsCache := cache.newRowCache("Logical_Switch")
+	lspCache := cache.newRowCache("Logical_Switch_Port")
+	for _, lsp := range lsps {
+		lspCache.set(lsp.UUID, lsp)
+	}
+	ls0 := &testLogicalSwitch{UUID: aUUID3, Name: "ls0", Ports: []string{aUUID0, aUUID1, "dangling"}}
+	ls1 := &testLogicalSwitch{UUID: "2f77b348-9768-4866-b761-89d5177ecda4", Name: "ls1", Ports: []string{aUUID1}}
+	lsCache.set(ls0.UUID, ls0)
+	lsCache.set(ls1.UUID, ls1)
+	cache.cache["Logical_Switch"] = lsCache
+	cache.cache["Logical_Switch_Port"] = lspCache
+	a := newAPI(cache)
+
+	t.Run("references", func(t *testing.T) {
+		var ports []testLogicalSwitchPort
+		require.Nil(t, a.References(ls0, &ls0.Ports, &ports))
+		assert.Equal(t, []testLogicalSwitchPort{*lsps[0], *lsps[1]}, ports)
+
+		var switches []testLogicalSwitch
+		assert.NotNil(t, a.References(ls0, &ls0.Ports, &switches), "wrong result type")
+		assert.NotNil(t, a.References(ls0, &ls0.Name, &ports), "not a reference")
+		assert.NotNil(t, a.References(ls0, &ls1.Ports, &ports), "not a field of the model")
+	})
+
+	t.Run("referenced by", func(t *testing.T) {
+		var switches []testLogicalSwitch
+		require.Nil(t, a.ReferencedBy(&testLogicalSwitchPort{Name: "lsp1"}, &switches))
+		assert.ElementsMatch(t, []testLogicalSwitch{*ls0, *ls1}, switches)
+
+		switches = nil
+		require.Nil(t, a.ReferencedBy(&testLogicalSwitchPort{UUID: aUUID2}, &switches))
+		assert.Empty(t, switches)
+		assert.Equal(t, ErrNotFound, a.ReferencedBy(&testLogicalSwitchPort{Name: "foo"}, &switches))
+
+		// references are updated along with the rows
+		lsCache.set(ls0.UUID, &testLogicalSwitch{UUID: aUUID3, Name: "ls0"})
+		lsCache.remove(ls1.UUID)
+		require.Nil(t, a.ReferencedBy(&testLogicalSwitchPort{Name: "lsp1"}, &switches))
+		assert.Empty(t, switches)
+		assert.Empty(t, lsCache.references)
+	})
+}
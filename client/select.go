@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// Select runs a select operation for every list of conditions generated by the Conditional
+// and populates a slice of Models given as parameter with the rows returned by the server
+// If fields are given, only their columns are retrieved, along with "_uuid", which is
+// used to return only once the rows that match more than one list of conditions
+func (a api) Select(ctx context.Context, result interface{}, fields ...interface{}) error {
+	if a.transact == nil {
+		return fmt.Errorf("select requires a client to run the operation on the server")
+	}
+	operations, err := a.SelectOperations(fields...)
+	if err != nil {
+		return err
+	}
+
+	resultPtr := reflect.ValueOf(result)
+	table, err := a.getTableFromSlice(result)
+	if err != nil {
+		return err
+	}
+	if a.cond.Table() != table {
+		return &InputTypeError{resultPtr.Type(),
+			fmt.Sprintf("Table derived from input type (%s) does not match Table from Condition (%s)", table, a.cond.Table())}
+	}
+
+	if len(operations) == 0 {
+		return nil
+	}
+
+	reply, err := a.transact(ctx, operations...)
+	if err != nil {
+		return err
+	}
+	if opErrs, err := ovsdb.CheckOperationResults(reply, operations); err != nil {
+		return &TransactionError{Errors: opErrs, err: err}
+	}
+
+	resultVal := resultPtr.Elem()
+	seen := make(map[string]bool)
+	for _, opResult := range reply[:len(operations)] {
+		for _, row := range opResult.Rows {
+			uuid, _ := row["_uuid"].(ovsdb.UUID)
+			if uuid.GoUUID != "" {
+				if seen[uuid.GoUUID] {
+					continue
+				}
+				seen[uuid.GoUUID] = true
+			}
+			elem, err := a.selectedModel(table, resultVal.Type().Elem(), row, uuid.GoUUID)
+			if err != nil {
+				return err
+			}
+			resultVal.Set(reflect.Append(resultVal, elem))
+		}
+	}
+	return nil
+}
+
+// SelectOperations returns the select operations that Select runs, so that they can be
+// part of a transaction with other operations
+func (a api) SelectOperations(fields ...interface{}) ([]ovsdb.Operation, error) {
+	conditions, err := a.cond.Generate()
+	if err != nil {
+		return nil, err
+	}
+	columns, err := a.selectColumns(a.cond.Table(), fields...)
+	if err != nil {
+		return nil, err
+	}
+	operations := make([]ovsdb.Operation, 0, len(conditions))
+	for _, condition := range conditions {
+		operations = append(operations, ovsdb.Operation{
+			Op:      opSelect,
+			Table:   a.cond.Table(),
+			Where:   condition,
+			Columns: columns,
+		})
+	}
+	return operations, nil
+}
+
+// selectedModel returns a model of the given type holding the data of a row returned by select
+func (a api) selectedModel(table string, modelType reflect.Type, row ovsdb.ResultRow, uuid string) (reflect.Value, error) {
+	model := reflect.New(modelType)
+	if err := a.cache.orm.getData(table, row, model.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	if uuid != "" {
+		info, err := newORMInfo(a.cache.orm.schema.Table(table), model.Interface())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if info.hasColumn("_uuid") {
+			if err := info.setField("_uuid", uuid); err != nil {
+				return reflect.Value{}, err
+			}
+		}
+	}
+	return model.Elem(), nil
+}
+
+// selectColumns returns the columns of the given fields, which must point to the fields
+// of the model of the Conditional, plus "_uuid". It returns nil if no field is given
+func (a api) selectColumns(table string, fields ...interface{}) ([]string, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	conditional, ok := a.cond.(modelConditional)
+	if !ok {
+		return nil, fmt.Errorf("fields can only be selected with conditions created from a model")
+	}
+	info, err := newORMInfo(a.cache.orm.schema.Table(table), conditional.baseModel())
+	if err != nil {
+		return nil, err
+	}
+	columns := []string{"_uuid"}
+	for _, field := range fields {
+		column, err := info.columnByPtr(field)
+		if err != nil {
+			return nil, err
+		}
+		if column != "_uuid" {
+			columns = append(columns, column)
+		}
+	}
+	return columns, nil
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPISelect(t *testing.T) {
+	cache := apiTestCache(t)
+	lsp0 := ovsdb.ResultRow{
+		"_uuid":        ovsdb.UUID{GoUUID: aUUID0},
+		"name":         "lsp0",
+		"type":         "someType",
+		"external_ids": ovsdb.OvsMap{GoMap: map[interface{}]interface{}{"foo": "bar"}},
+	}
+	lsp1 := ovsdb.ResultRow{
+		"_uuid": ovsdb.UUID{GoUUID: aUUID1},
+		"name":  "lsp1",
+		"type":  "someType",
+	}
+
+	var sent []ovsdb.Operation
+	var reply []ovsdb.OperationResult
+	var transactErr error
+	transact := func(ctx context.Context, ops ...ovsdb.Operation) ([]ovsdb.OperationResult, error) {
+		sent = ops
+		return reply, transactErr
+	}
+	a := newTransactAPI(cache, transact, false)
+
+	t.Run("single condition", func(t *testing.T) {
+		sent = nil
+		reply = []ovsdb.OperationResult{{Rows: []ovsdb.ResultRow{lsp0, lsp1}}}
+		model := testLogicalSwitchPort{}
+		var result []testLogicalSwitchPort
+		err := a.Where(&model, Condition{Field: &model.Type, Function: ovsdb.ConditionEqual, Value: "someType"}).Select(context.Background(), &result)
+		require.Nil(t, err)
+		assert.Equal(t, []ovsdb.Operation{{
+			Op:    opSelect,
+			Table: "Logical_Switch_Port",
+			Where: []ovsdb.Condition{ovsdb.NewCondition("type", ovsdb.ConditionEqual, "someType")},
+		}}, sent)
+		assert.Equal(t, []testLogicalSwitchPort{
+			{UUID: aUUID0, Name: "lsp0", Type: "someType", ExternalIds: map[string]string{"foo": "bar"}},
+			{UUID: aUUID1, Name: "lsp1", Type: "someType"},
+		}, result)
+	})
+
+	t.Run("rows matched by several conditions are returned once", func(t *testing.T) {
+		sent = nil
+		reply = []ovsdb.OperationResult{{Rows: []ovsdb.ResultRow{lsp0}}, {Rows: []ovsdb.ResultRow{lsp0, lsp1}}}
+		model := testLogicalSwitchPort{}
+		var result []testLogicalSwitchPort
+		err := a.Where(&model,
+			Condition{Field: &model.Name, Function: ovsdb.ConditionEqual, Value: "lsp0"},
+			Condition{Field: &model.Type, Function: ovsdb.ConditionEqual, Value: "someType"},
+		).Select(context.Background(), &result)
+		require.Nil(t, err)
+		assert.Len(t, sent, 2)
+		assert.Len(t, result, 2)
+	})
+
+	t.Run("projection", func(t *testing.T) {
+		sent = nil
+		reply = []ovsdb.OperationResult{{Rows: []ovsdb.ResultRow{{"_uuid": ovsdb.UUID{GoUUID: aUUID0}, "name": "lsp0"}}}}
+		model := testLogicalSwitchPort{Name: "lsp0"}
+		var result []testLogicalSwitchPort
+		err := a.Where(&model).Select(context.Background(), &result, &model.Name)
+		require.Nil(t, err)
+		require.Len(t, sent, 1)
+		assert.Equal(t, []string{"_uuid", "name"}, sent[0].Columns)
+		assert.Equal(t, []testLogicalSwitchPort{{UUID: aUUID0, Name: "lsp0"}}, result)
+	})
+
+	t.Run("projection without model", func(t *testing.T) {
+		sent = nil
+		model := testLogicalSwitchPort{}
+		var result []testLogicalSwitchPort
+		err := a.WhereCache(func(*testLogicalSwitchPort) bool { return true }).Select(context.Background(), &result, &model.Name)
+		assert.NotNil(t, err)
+		assert.Nil(t, sent)
+	})
+
+	t.Run("wrong table", func(t *testing.T) {
+		sent = nil
+		model := testLogicalSwitchPort{Name: "lsp0"}
+		var result []testLogicalSwitch
+		err := a.Where(&model).Select(context.Background(), &result)
+		assert.IsType(t, &InputTypeError{}, err)
+		assert.Nil(t, sent)
+	})
+
+	t.Run("operation error", func(t *testing.T) {
+		reply = []ovsdb.OperationResult{{Error: "constraint violation", Details: "bad"}}
+		model := testLogicalSwitchPort{Name: "lsp0"}
+		var result []testLogicalSwitchPort
+		err := a.Where(&model).Select(context.Background(), &result)
+		assert.IsType(t, &TransactionError{}, err)
+		assert.Empty(t, result)
+	})
+
+	t.Run("without client", func(t *testing.T) {
+		model := testLogicalSwitchPort{Name: "lsp0"}
+		var result []testLogicalSwitchPort
+		err := newAPI(cache).Where(&model).Select(context.Background(), &result)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+const (
+	serverDB = "_Server"
+	// serverMonitorID is the json-value of the monitor of the _Server database
+	serverMonitorID = "_Server"
+	// serverDatabaseTable is the table of the _Server database that holds the status of each database
+	serverDatabaseTable = "Database"
+	clusteredModel      = "clustered"
+)
+
+// serverDatabase is the model of the Database table of the _Server database
+type serverDatabase struct {
+	UUID      string `ovs:"_uuid"`
+	Name      string `ovs:"name"`
+	Model     string `ovs:"model"`
+	Connected bool   `ovs:"connected"`
+	Leader    bool   `ovs:"leader"`
+	Index     []int  `ovs:"index"`
+}
+
+// monitorServer monitors the status of the database in the _Server database of the current
+// connection and returns an error if the server is not suitable for the client
+func (ovs *OvsdbClient) monitorServer(ctx context.Context) error {
+	schema, err := ovs.GetSchema(ctx, serverDB)
+	if err != nil {
+		return fmt.Errorf("failed to get the schema of the %s database: %w", serverDB, err)
+	}
+	ovs.rpcMutex.Lock()
+	ovs.serverORM = newORM(schema)
+	ovs.rpcMutex.Unlock()
+
+	requests := map[string]ovsdb.MonitorRequest{
+		serverDatabaseTable: {
+			Columns: []string{"name", "model", "connected", "leader", "index"},
+			Select:  ovsdb.NewDefaultMonitorSelect(),
+		},
+	}
+	args := ovsdb.NewMonitorArgs(serverDB, serverMonitorID, requests)
+	var response map[string]map[string]ovsdb.RowUpdate
+	if err := ovs.call(ctx, monitorRPC, args, &response); err != nil {
+		return fmt.Errorf("failed to monitor the %s database: %w", serverDB, err)
+	}
+	found, err := ovs.checkServer(getTableUpdatesFromRawUnmarshal(response))
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("database %s not found in the %s database", ovs.dbModel.Name(), serverDB)
+	}
+	return nil
+}
+
+// handleServerUpdate checks the status of the database after an update notification of the
+// _Server database. If the server is no longer suitable, the connection is closed
+func (ovs *OvsdbClient) handleServerUpdate(tableUpdates ovsdb.TableUpdates) {
+	if _, err := ovs.checkServer(tableUpdates); err != nil {
+		ovs.options.logger.Printf("dropping connection: %v", err)
+		ovs.rpc().Close()
+	}
+}
+
+// checkServer returns an error if the status of the database in the updates of the Database
+// table shows that the server is not the leader of its cluster, is disconnected from it or
+// has fallen behind a server the client was connected to. It also returns whether the updates
+// include the status of the database
+func (ovs *OvsdbClient) checkServer(tableUpdates ovsdb.TableUpdates) (bool, error) {
+	ovs.rpcMutex.Lock()
+	defer ovs.rpcMutex.Unlock()
+	found := false
+	for _, row := range tableUpdates.Updates[serverDatabaseTable].Rows {
+		if len(row.New.Fields) == 0 {
+			continue
+		}
+		var database serverDatabase
+		if err := ovs.serverORM.getRowData(serverDatabaseTable, &row.New, &database); err != nil {
+			return found, err
+		}
+		if database.Name != ovs.dbModel.Name() {
+			continue
+		}
+		found = true
+		if database.Model != clusteredModel {
+			continue
+		}
+		if !database.Connected {
+			return found, fmt.Errorf("database %s is not connected to its cluster", database.Name)
+		}
+		if !database.Leader {
+			return found, fmt.Errorf("database %s is not the leader of its cluster", database.Name)
+		}
+		if len(database.Index) > 0 {
+			if database.Index[0] < ovs.serverIndex {
+				return found, fmt.Errorf("database %s is stale: its index %d is older than %d",
+					database.Name, database.Index[0], ovs.serverIndex)
+			}
+			ovs.serverIndex = database.Index[0]
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,141 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// snapshot is the serialized form of the contents of a TableCache
+type snapshot struct {
+	Schema ovsdb.DatabaseSchema `json:"schema"`
+	// LastTxnID is only known if the cache is maintained by monitors issued with MonitorCondSince
+	LastTxnID string                          `json:"last_txn_id,omitempty"`
+	Tables    map[string]map[string]ovsdb.Row `json:"tables"`
+}
+
+// SaveSnapshot writes the contents of the cache, along with the schema of the database
+// and the id of the last transaction, if known, so that they can be restored with LoadSnapshot
+// Ephemeral columns, which the server does not persist either, are left out. As the changes
+// made since the snapshot was saved would not include them, the id of the last transaction
+// is not saved if any of them had a value other than the default one, so that the contents
+// of the snapshot are reconciled with the server's once loaded
+func (t *TableCache) SaveSnapshot(w io.Writer) error {
+	t.cacheMutex.RLock()
+	defer t.cacheMutex.RUnlock()
+	if t.orm.schema.Name == "" {
+		return fmt.Errorf("cannot save a snapshot of a cache without schema")
+	}
+	snap := snapshot{
+		Schema:    *t.orm.schema,
+		LastTxnID: t.lastTxnID,
+		Tables:    make(map[string]map[string]ovsdb.Row, len(t.cache)),
+	}
+	for table, rowCache := range t.cache {
+		tableSchema := t.orm.schema.Table(table)
+		if tableSchema == nil {
+			return NewErrNoTable(table)
+		}
+		rowCache.mutex.RLock()
+		rows := make(map[string]ovsdb.Row, len(rowCache.cache))
+		for uuid, model := range rowCache.cache {
+			row, err := t.orm.newRow(table, model)
+			if err != nil {
+				rowCache.mutex.RUnlock()
+				return fmt.Errorf("table %s, row %s: %v", table, uuid, err)
+			}
+			// the row only has the columns whose value is not the default one
+			for column := range row {
+				if columnSchema := tableSchema.Column(column); columnSchema != nil && columnSchema.Ephemeral() {
+					delete(row, column)
+					snap.LastTxnID = ""
+				}
+			}
+			rows[uuid] = ovsdb.Row{Fields: row}
+		}
+		rowCache.mutex.RUnlock()
+		snap.Tables[table] = rows
+	}
+	return json.NewEncoder(w).Encode(snap)
+}
+
+// LoadSnapshot populates an empty cache with the contents saved with SaveSnapshot so that they
+// can be read before the client connects. The schema of the snapshot is used until the one of the
+// server is known and the id of the last transaction allows monitors issued with MonitorCondSince
+// to only request the changes made since the snapshot was saved. Otherwise, or if the schema of the
+// server has a different version, the contents of the cache are reconciled with the server's
+// Handlers registered beforehand receive an add event for every row loaded, which blocks
+// if they do not fit in the event buffer and the cache is not running, see OvsdbClient.LoadSnapshot
+func (t *TableCache) LoadSnapshot(r io.Reader) error {
+	var snap snapshot
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return fmt.Errorf("invalid snapshot: %v", err)
+	}
+	if errs := t.dbModel.Validate(&snap.Schema); len(errs) > 0 {
+		var combined []string
+		for _, err := range errs {
+			combined = append(combined, err.Error())
+		}
+		return fmt.Errorf("snapshot validation error (%d): %s", len(errs), strings.Join(combined, ". "))
+	}
+
+	// the cache does not expect invalid rows
+	o := newORM(&snap.Schema)
+	tableUpdates := ovsdb.TableUpdates{Updates: make(map[string]ovsdb.TableUpdate, len(snap.Tables))}
+	for table, rows := range snap.Tables {
+		tableUpdate := ovsdb.TableUpdate{Rows: make(map[string]ovsdb.RowUpdate, len(rows))}
+		for uuid, row := range rows {
+			model, err := t.dbModel.newModel(table)
+			if err == nil {
+				err = o.getRowData(table, &row, model)
+			}
+			if err != nil {
+				return fmt.Errorf("invalid snapshot: table %s, row %s: %v", table, uuid, err)
+			}
+			tableUpdate.Rows[uuid] = ovsdb.RowUpdate{New: row}
+		}
+		tableUpdates.Updates[table] = tableUpdate
+	}
+
+	t.cacheMutex.Lock()
+	for _, rowCache := range t.cache {
+		if rowCache.Len() > 0 {
+			t.cacheMutex.Unlock()
+			return fmt.Errorf("cannot load a snapshot into a cache that is not empty")
+		}
+	}
+	schema := t.orm.schema
+	if schema.Name != "" && schema.Version != snap.Schema.Version {
+		t.cacheMutex.Unlock()
+		return fmt.Errorf("snapshot schema version %s does not match %s", snap.Schema.Version, schema.Version)
+	}
+	if schema.Name == "" {
+		t.orm.schema = &snap.Schema
+	}
+	t.unreconciled = make(map[string]bool, len(snap.Tables))
+	for table := range snap.Tables {
+		t.unreconciled[table] = true
+	}
+	t.cacheMutex.Unlock()
+
+	t.populate(tableUpdates)
+	t.setLastTransactionID(snap.LastTxnID)
+	return nil
+}
+
+// LoadSnapshot loads into the cache a snapshot saved with TableCache.SaveSnapshot, see
+// TableCache.LoadSnapshot. It must be called before Connect. The events of the rows loaded
+// are delivered to the handlers right away
+func (ovs *OvsdbClient) LoadSnapshot(r io.Reader) error {
+	ovs.monitorsMutex.Lock()
+	connected := ovs.connected
+	ovs.monitorsMutex.Unlock()
+	if connected {
+		return fmt.Errorf("cannot load a snapshot once the client is connected")
+	}
+	ovs.startCache()
+	return ovs.Cache.LoadSnapshot(r)
+}
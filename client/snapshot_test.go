@@ -0,0 +1,181 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	snapshotBr0  = "2f77b348-9768-4866-b761-89d5177ecda0"
+	snapshotBr1  = "2f77b348-9768-4866-b761-89d5177ecda1"
+	snapshotRoot = "2f77b348-9768-4866-b761-89d5177ecdaa"
+)
+
+// testSnapshot returns a snapshot of a cache with a bridge and the root row
+func testSnapshot(t *testing.T) (*TableCache, []byte) {
+	var schema ovsdb.DatabaseSchema
+	require.Nil(t, json.Unmarshal(testServerSchema, &schema))
+	tc, err := newTableCache(&schema, defDB)
+	require.Nil(t, err)
+	var raw map[string]map[string]ovsdb.RowUpdate2
+	require.Nil(t, json.Unmarshal([]byte(`{
+		"Bridge": {"`+snapshotBr0+`": {"initial": {"name": "br0", "external_ids": ["map", [["owner", "me"]]]}}},
+		"Open_vSwitch": {"`+snapshotRoot+`": {"initial": {"bridges": ["uuid", "`+snapshotBr0+`"]}}}}`), &raw))
+	tc.populate2(getTableUpdates2FromRawUnmarshal(raw))
+	tc.setLastTransactionID("txn1")
+	var buf bytes.Buffer
+	require.Nil(t, tc.SaveSnapshot(&buf))
+	return tc, buf.Bytes()
+}
+
+func TestTableCache_snapshot(t *testing.T) {
+	tc, snap := testSnapshot(t)
+
+	empty, err := newTableCache(nil, defDB)
+	require.Nil(t, err)
+	assert.NotNil(t, empty.SaveSnapshot(&bytes.Buffer{}))
+
+	loaded, err := newTableCache(nil, defDB)
+	require.Nil(t, err)
+	require.Nil(t, loaded.LoadSnapshot(bytes.NewReader(snap)))
+	for _, table := range []string{"Bridge", "Open_vSwitch"} {
+		assert.ElementsMatch(t, tc.Table(table).Rows(), loaded.Table(table).Rows())
+		for _, uuid := range tc.Table(table).Rows() {
+			assert.Equal(t, tc.Table(table).Row(uuid), loaded.Table(table).Row(uuid))
+		}
+	}
+	assert.Equal(t, "txn1", loaded.LastTransactionID())
+	rows, indexed, err := loaded.Table("Bridge").rowsByModel(&bridgeType{Name: "br0"}, true)
+	require.Nil(t, err)
+	assert.True(t, indexed)
+	assert.Equal(t, []string{snapshotBr0}, rows)
+	assert.NotNil(t, loaded.LoadSnapshot(bytes.NewReader(snap)), "the cache is not empty")
+
+	// the rows are kept, and reindexed, if the schema of the server has another version
+	var schema ovsdb.DatabaseSchema
+	require.Nil(t, json.Unmarshal(testServerSchema, &schema))
+	schema.Version = "9.0.0"
+	loaded.setSchema(&schema)
+	assert.Equal(t, "", loaded.LastTransactionID())
+	rows, _, err = loaded.Table("Bridge").rowsByModel(&bridgeType{Name: "br0"}, true)
+	require.Nil(t, err)
+	assert.Equal(t, []string{snapshotBr0}, rows)
+
+	tests := []struct {
+		name string
+		snap string
+	}{
+		{"invalid json", `{"schema": `},
+		{"other database", strings.Replace(string(snap), `"name":"Open_vSwitch"`, `"name":"OVN_Northbound"`, 1)},
+		{"invalid row", strings.Replace(string(snap), `"name":"br0"`, `"name":0`, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc, err := newTableCache(nil, defDB)
+			require.Nil(t, err)
+			assert.NotNil(t, tc.LoadSnapshot(strings.NewReader(tt.snap)))
+			assert.Nil(t, tc.Table("Bridge"))
+		})
+	}
+
+	// the version of the schema must match if it is already known
+	other, err := newTableCache(&schema, defDB)
+	require.Nil(t, err)
+	assert.NotNil(t, other.LoadSnapshot(bytes.NewReader(snap)))
+}
+
+func TestTableCache_snapshotEphemeral(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	require.Nil(t, json.Unmarshal(testServerSchema, &schema))
+	tc, err := newTableCache(&schema, defDB)
+	require.Nil(t, err)
+	var raw map[string]map[string]ovsdb.RowUpdate2
+	require.Nil(t, json.Unmarshal([]byte(`{
+		"Bridge": {"`+snapshotBr0+`": {"initial": {"name": "br0", "status": ["map", [["state", "up"]]]}}}}`), &raw))
+	tc.populate2(getTableUpdates2FromRawUnmarshal(raw))
+	tc.setLastTransactionID("txn1")
+	var buf bytes.Buffer
+	require.Nil(t, tc.SaveSnapshot(&buf))
+
+	// "status" is ephemeral, so it is not saved and the snapshot must be reconciled
+	loaded, err := newTableCache(nil, defDB)
+	require.Nil(t, err)
+	require.Nil(t, loaded.LoadSnapshot(&buf))
+	assert.Equal(t, &bridgeType{UUID: snapshotBr0, Name: "br0"}, loaded.Table("Bridge").Row(snapshotBr0))
+	assert.Equal(t, "", loaded.LastTransactionID())
+}
+
+func TestWarmStart(t *testing.T) {
+	_, snap := testSnapshot(t)
+	bridgeMonitor := map[string]ovsdb.MonitorRequest{
+		"Bridge": {Columns: []string{"name"}, Select: ovsdb.NewDefaultMonitorSelect()},
+	}
+	newClient := func(t *testing.T, server *testOvsdbServer) (*OvsdbClient, chan string) {
+		ovs, err := NewOvsdbClient(defDB, WithEndpoint(server.endpoint()))
+		require.Nil(t, err)
+		events := make(chan string, 10)
+		ovs.Cache.AddEventHandler(&EventHandlerFuncs{
+			AddFunc: func(table string, model Model) {
+				if b, ok := model.(*bridgeType); ok {
+					events <- "add " + b.Name
+				}
+			},
+			UpdateFunc: func(table string, old, new Model) {
+				events <- "update " + old.(*bridgeType).Name + " " + new.(*bridgeType).Name
+			},
+			DeleteFunc: func(table string, model Model) {
+				if b, ok := model.(*bridgeType); ok {
+					events <- "delete " + b.Name
+				}
+			},
+		})
+		require.Nil(t, ovs.LoadSnapshot(bytes.NewReader(snap)))
+		assert.Equal(t, []string{"add br0"}, receiveEvents(t, events, 1))
+		return ovs, events
+	}
+
+	t.Run("monitor issued by Connect", func(t *testing.T) {
+		server := newTestOvsdbServer(t)
+		server.setRow("Bridge", snapshotBr0, map[string]interface{}{"name": "br0-renamed"})
+		server.setRow("Bridge", snapshotBr1, map[string]interface{}{"name": "br1"})
+		server.start()
+		ovs, events := newClient(t, server)
+		defer ovs.Disconnect()
+
+		// the contents of the snapshot can be read before connecting
+		var bridges []bridgeType
+		require.Nil(t, ovs.List(&bridges))
+		require.Len(t, bridges, 1)
+		assert.Equal(t, "br0", bridges[0].Name)
+
+		require.Nil(t, ovs.Monitor(context.Background(), "", bridgeMonitor))
+		require.Nil(t, ovs.Connect(context.Background()))
+		assert.NotNil(t, ovs.LoadSnapshot(bytes.NewReader(snap)))
+		// only the differences with the server are notified
+		assert.ElementsMatch(t, []string{"update br0 br0-renamed", "add br1"}, receiveEvents(t, events, 2))
+	})
+
+	t.Run("monitor issued after Connect", func(t *testing.T) {
+		server := newTestOvsdbServer(t)
+		server.setRow("Bridge", snapshotBr1, map[string]interface{}{"name": "br1"})
+		server.start()
+		ovs, events := newClient(t, server)
+		defer ovs.Disconnect()
+
+		require.Nil(t, ovs.Connect(context.Background()))
+		require.Nil(t, ovs.Monitor(context.Background(), "", bridgeMonitor))
+		// br0 was deleted while the client was not connected
+		assert.ElementsMatch(t, []string{"delete br0", "add br1"}, receiveEvents(t, events, 2))
+		var bridges []bridgeType
+		require.Nil(t, ovs.List(&bridges))
+		require.Len(t, bridges, 1)
+		assert.Equal(t, "br1", bridges[0].Name)
+	})
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+)
+
+// setLoaded records that the initial contents of the given monitors are in the cache
+// They are synced once the events queued so far have been delivered
+func (ovs *OvsdbClient) setLoaded(keys ...string) {
+	seq := ovs.Cache.eventProcessor.lastSeq()
+	ovs.monitorsMutex.Lock()
+	for _, key := range keys {
+		if m, ok := ovs.monitors[key]; ok && !m.loaded {
+			m.loaded = true
+			m.synced = seq
+		}
+	}
+	ovs.monitorsMutex.Unlock()
+	ovs.Cache.eventProcessor.notifySynced()
+}
+
+// HasSynced returns whether the initial contents of the monitor with the given json-value
+// have been loaded into the cache and the resulting events delivered to every handler
+// It remains true after a reconnection, while the monitor is re-issued
+func (ovs *OvsdbClient) HasSynced(jsonContext interface{}) bool {
+	key, err := monitorKey(jsonContext)
+	if err != nil {
+		return false
+	}
+	ovs.monitorsMutex.Lock()
+	m, ok := ovs.monitors[key]
+	if !ok || !m.loaded {
+		ovs.monitorsMutex.Unlock()
+		return false
+	}
+	seq := m.synced
+	ovs.monitorsMutex.Unlock()
+	return ovs.Cache.eventProcessor.syncedTo(seq, nil)
+}
+
+// HandlerHasSynced returns whether the handler has been delivered the events that resulted
+// from the initial contents of every monitor. It is false while any of them is pending
+func (ovs *OvsdbClient) HandlerHasSynced(handler EventHandler) bool {
+	seq, ok := ovs.syncPoint()
+	return ok && ovs.Cache.eventProcessor.syncedTo(seq, handler)
+}
+
+// WaitForCacheSync blocks until every monitor HasSynced or the context is done
+func (ovs *OvsdbClient) WaitForCacheSync(ctx context.Context) error {
+	return ovs.Cache.eventProcessor.waitSynced(ctx, func() bool {
+		seq, ok := ovs.syncPoint()
+		return ok && ovs.Cache.eventProcessor.syncedTo(seq, nil)
+	})
+}
+
+// WaitForHandlerSync blocks until the handler HandlerHasSynced or the context is done
+func (ovs *OvsdbClient) WaitForHandlerSync(ctx context.Context, handler EventHandler) error {
+	return ovs.Cache.eventProcessor.waitSynced(ctx, func() bool {
+		return ovs.HandlerHasSynced(handler)
+	})
+}
+
+// syncPoint returns the sequence number of the last event that resulted from the initial
+// contents of the monitors. It returns false if any of them has not been loaded yet
+func (ovs *OvsdbClient) syncPoint() (uint64, bool) {
+	ovs.monitorsMutex.Lock()
+	defer ovs.monitorsMutex.Unlock()
+	var seq uint64
+	for _, m := range ovs.monitors {
+		if !m.loaded {
+			return 0, false
+		}
+		if m.synced > seq {
+			seq = m.synced
+		}
+	}
+	return seq, true
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForCacheSync(t *testing.T) {
+	const (
+		br0 = "2f77b348-9768-4866-b761-89d5177ecda0"
+		br1 = "2f77b348-9768-4866-b761-89d5177ecda1"
+	)
+	for _, mode := range []EventDeliveryMode{EventDeliveryBlock, EventDeliveryCoalesce} {
+		server := newTestOvsdbServer(t)
+		server.setRow("Bridge", br0, map[string]interface{}{"name": "br0"})
+		server.setRow("Bridge", br1, map[string]interface{}{"name": "br1"})
+		server.start()
+
+		ovs, err := NewOvsdbClient(defDB, WithEndpoint(server.endpoint()), WithEventDelivery(mode))
+		require.Nil(t, err)
+		defer ovs.Disconnect()
+
+		var added int32
+		serial := &EventHandlerFuncs{
+			AddFunc: func(string, Model) {
+				atomic.AddInt32(&added, 1)
+			},
+		}
+		ovs.Cache.AddEventHandler(serial)
+		release := make(chan struct{})
+		stuck := &EventHandlerFuncs{
+			AddFunc: func(string, Model) {
+				<-release
+			},
+		}
+		require.Nil(t, ovs.Cache.AddEventHandlerFor(stuck, nil, "Bridge"))
+
+		require.Nil(t, ovs.MonitorAll(context.Background(), "mon"))
+		assert.False(t, ovs.HasSynced("mon"))
+		assert.False(t, ovs.HandlerHasSynced(serial))
+		require.Nil(t, ovs.Connect(context.Background()))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		require.Nil(t, ovs.WaitForHandlerSync(ctx, serial))
+		cancel()
+		assert.Equal(t, int32(2), atomic.LoadInt32(&added))
+		assert.False(t, ovs.HasSynced("mon"))
+		assert.False(t, ovs.HandlerHasSynced(stuck))
+
+		ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+		err = ovs.WaitForCacheSync(ctx)
+		cancel()
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+		close(release)
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+		require.Nil(t, ovs.WaitForCacheSync(ctx))
+		cancel()
+		assert.True(t, ovs.HasSynced("mon"))
+		assert.True(t, ovs.HandlerHasSynced(stuck))
+		assert.False(t, ovs.HasSynced("other"))
+	}
+}
+
+func TestCacheResync(t *testing.T) {
+	const br0 = "2f77b348-9768-4866-b761-89d5177ecda0"
+	server := newTestOvsdbServer(t)
+	server.setRow("Bridge", br0, map[string]interface{}{"name": "br0"})
+	server.start()
+
+	_, err := NewOvsdbClient(defDB, WithEndpoint(server.endpoint()), WithCacheResync(0))
+	assert.NotNil(t, err)
+	ovs, err := NewOvsdbClient(defDB, WithEndpoint(server.endpoint()), WithCacheResync(20*time.Millisecond))
+	require.Nil(t, err)
+	defer ovs.Disconnect()
+
+	events := make(chan string, 10)
+	ovs.Cache.AddEventHandler(&EventHandlerFuncs{
+		AddFunc: func(table string, model Model) {
+			events <- "add " + model.(*bridgeType).Name
+		},
+		UpdateFunc: func(table string, old, new Model) {
+			if old == new {
+				events <- "resync " + new.(*bridgeType).Name
+			}
+		},
+	})
+	require.Nil(t, ovs.MonitorAll(context.Background(), ""))
+	require.Nil(t, ovs.Connect(context.Background()))
+	assert.Equal(t, []string{"add br0", "resync br0", "resync br0"}, receiveEvents(t, events, 3))
+}
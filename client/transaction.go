@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// Transaction accumulates operations that are committed atomically to the database
+// Operations are added with Create, for new rows, and Add, which takes the output of
+// any other operation of the API. E.g:
+//
+//	txn := ovs.NewTransaction()
+//	err := txn.Create(&bridge)
+//	err = txn.Add(ovs.Where(&ovsRow).Mutate(&ovsRow, mutations))
+//	uuids, err := txn.Commit(ctx)
+//
+// A Transaction is not safe for concurrent use
+type Transaction struct {
+	client     *OvsdbClient
+	operations []ovsdb.Operation
+	// created holds the models given to Create indexed by the position of their insert operation
+	created map[int]Model
+}
+
+// TransactionError is returned by Commit when the server rejects the transaction
+// Errors contains the typed error of every operation that failed
+type TransactionError struct {
+	Errors []ovsdb.OperationError
+	err    error
+}
+
+func (e *TransactionError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	if len(msgs) == 0 {
+		return fmt.Sprintf("transaction failed: %v", e.err)
+	}
+	return fmt.Sprintf("transaction failed: %v: %s", e.err, strings.Join(msgs, ", "))
+}
+
+// Unwrap returns the error that made the transaction fail
+func (e *TransactionError) Unwrap() error {
+	return e.err
+}
+
+// NewTransaction returns an empty Transaction
+func (ovs *OvsdbClient) NewTransaction() *Transaction {
+	return &Transaction{
+		client:  ovs,
+		created: make(map[int]Model),
+	}
+}
+
+// Create adds the operations to insert the given models
+// Once the transaction is committed, the UUID assigned by the server is set in each model
+// As with the API, the content of the "_uuid" field, if any, is used as named-uuid so that
+// the row can be referenced by other operations of the transaction
+func (t *Transaction) Create(models ...Model) error {
+	for _, model := range models {
+		ops, err := t.client.Create(model)
+		if err != nil {
+			return err
+		}
+		t.created[len(t.operations)] = model
+		t.operations = append(t.operations, ops...)
+	}
+	return nil
+}
+
+// Add adds the given operations, along with the error that resulted from building them,
+// so that the output of the API can be passed directly. E.g:
+//
+//	err := txn.Add(ovs.Where(&bridge).Delete())
+func (t *Transaction) Add(operations []ovsdb.Operation, err error) error {
+	if err != nil {
+		return err
+	}
+	t.operations = append(t.operations, operations...)
+	return nil
+}
+
+// Operations returns the operations of the transaction
+func (t *Transaction) Operations() []ovsdb.Operation {
+	return t.operations
+}
+
+// Commit sends the operations of the transaction to the server
+// It returns the UUIDs assigned to the rows inserted with a named-uuid, indexed by it
+// If any of the operations fails, the returned error is a *TransactionError
+func (t *Transaction) Commit(ctx context.Context) (map[string]string, error) {
+	return t.commit(ctx, t.client.Transact)
+}
+
+// CommitAndWait works like Commit but also waits until the cache reflects the changes
+// made by the transaction, as TransactAndWait does
+func (t *Transaction) CommitAndWait(ctx context.Context) (map[string]string, error) {
+	return t.commit(ctx, t.client.TransactAndWait)
+}
+
+func (t *Transaction) commit(ctx context.Context, transact func(context.Context, ...ovsdb.Operation) ([]ovsdb.OperationResult, error)) (map[string]string, error) {
+	if len(t.operations) == 0 {
+		return nil, fmt.Errorf("transaction has no operations")
+	}
+	reply, err := transact(ctx, t.operations...)
+	if err != nil {
+		return nil, err
+	}
+	opErrs, err := ovsdb.CheckOperationResults(reply, t.operations)
+	if err != nil {
+		return nil, &TransactionError{Errors: opErrs, err: err}
+	}
+
+	uuids := make(map[string]string)
+	for i, model := range t.created {
+		uuid := reply[i].UUID.GoUUID
+		if name := t.operations[i].UUIDName; name != "" {
+			uuids[name] = uuid
+		}
+		if err := t.setUUID(t.operations[i].Table, model, uuid); err != nil {
+			return uuids, err
+		}
+	}
+	return uuids, nil
+}
+
+// setUUID sets the "_uuid" field of a model
+func (t *Transaction) setUUID(table string, model Model, uuid string) error {
+	info, err := newORMInfo(t.client.Cache.orm.schema.Table(table), model)
+	if err != nil {
+		return err
+	}
+	return info.setField("_uuid", uuid)
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/cenkalti/rpc2"
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransaction(t *testing.T) {
+	const (
+		br0     = "2f77b348-9768-4866-b761-89d5177ecda0"
+		br1     = "2f77b348-9768-4866-b761-89d5177ecda1"
+		rootOvs = "2f77b348-9768-4866-b761-89d5177ecdaa"
+	)
+	tests := []struct {
+		name    string
+		results string
+		uuids   map[string]string
+		errs    []ovsdb.OperationError
+	}{
+		{
+			name:    "success",
+			results: `[{"uuid": ["uuid", "` + br0 + `"]}, {"uuid": ["uuid", "` + br1 + `"]}, {"count": 1}]`,
+			uuids:   map[string]string{"br0": br0},
+		},
+		{
+			name:    "operation error",
+			results: `[{"uuid": ["uuid", "` + br0 + `"]}, {"error": "constraint violation", "details": "duplicate name"}, {}]`,
+			errs:    []ovsdb.OperationError{&ovsdb.ConstraintViolation{}},
+		},
+		{
+			name:    "commit error",
+			results: `[{"uuid": ["uuid", "` + br0 + `"]}, {"uuid": ["uuid", "` + br1 + `"]}, {"count": 1}, {"error": "referential integrity violation"}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ops []interface{}
+			server := newTestOvsdbServer(t)
+			server.handlers["transact"] = func(_ *rpc2.Client, args []interface{}, reply *[]interface{}) error {
+				ops = args[1:]
+				return json.Unmarshal([]byte(tt.results), reply)
+			}
+			server.start()
+
+			ovs, err := Connect(context.Background(), server.endpoint(), defDB, nil)
+			require.Nil(t, err)
+			defer ovs.Disconnect()
+
+			txn := ovs.NewTransaction()
+			_, err = txn.Commit(context.Background())
+			assert.NotNil(t, err)
+
+			named := &bridgeType{UUID: "br0", Name: "br0"}
+			anonymous := &bridgeType{Name: "br1"}
+			require.Nil(t, txn.Create(named, anonymous))
+			rootModel := &ovsType{UUID: rootOvs}
+			require.Nil(t, txn.Add(ovs.Where(rootModel).Mutate(rootModel, []Mutation{
+				{Field: &rootModel.Bridges, Mutator: ovsdb.MutateOperationInsert, Value: []string{"br0"}},
+			})))
+			assert.NotNil(t, txn.Add(ovs.Where(&bridgeType{}).Delete()))
+			require.Len(t, txn.Operations(), 3)
+
+			uuids, err := txn.Commit(context.Background())
+			require.Len(t, ops, 3)
+			if tt.uuids == nil {
+				require.NotNil(t, err)
+				var txnErr *TransactionError
+				require.True(t, errors.As(err, &txnErr))
+				require.Len(t, txnErr.Errors, len(tt.errs))
+				for i := range tt.errs {
+					assert.IsType(t, tt.errs[i], txnErr.Errors[i])
+				}
+				assert.Equal(t, "br0", named.UUID)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.uuids, uuids)
+			assert.Equal(t, br0, named.UUID)
+			assert.Equal(t, br1, anonymous.UUID)
+		})
+	}
+}
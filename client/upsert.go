@@ -0,0 +1,161 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// Upsert returns the operations that create the model or, if the cache has a row with its
+// values in the columns of an index of the schema, update that row as Update does with the
+// optional fields (pointers to fields in the model). The index used is the first one of the
+// schema for which the model has non-default values. The choice is made on the cache, so it
+// is guarded by a wait operation: the transaction fails with ovsdb.TimedOut if, since the
+// cache was read, a row with those values was created or the one to update was deleted or
+// got other values in the index, and OvsdbClient.RetryTransaction can run it again
+func (a api) Upsert(model Model, fields ...interface{}) ([]ovsdb.Operation, error) {
+	table, err := a.getTableFromModel(model)
+	if err != nil {
+		return nil, err
+	}
+	tableSchema := a.cache.orm.schema.Table(table)
+	if tableSchema == nil {
+		return nil, NewErrNoTable(table)
+	}
+	info, err := newORMInfo(tableSchema, model)
+	if err != nil {
+		return nil, err
+	}
+	index, err := upsertIndex(info)
+	if err != nil {
+		return nil, err
+	}
+
+	indexRow := make(map[string]interface{}, len(index))
+	var where []ovsdb.Condition
+	for _, column := range index {
+		value, err := info.fieldByColumn(column)
+		if err != nil {
+			return nil, err
+		}
+		ovsValue, err := ovsdb.NativeToOvs(tableSchema.Column(column), value)
+		if err != nil {
+			return nil, fmt.Errorf("table %s, column %s: failed to generate ovs element. %s", table, column, err.Error())
+		}
+		indexRow[column] = ovsValue
+		where = append(where, ovsdb.NewCondition(column, ovsdb.ConditionEqual, ovsValue))
+	}
+
+	uuid, err := a.cachedRowByIndex(table, info, index)
+	if err != nil {
+		return nil, err
+	}
+	if uuid == "" {
+		insert, err := a.Create(model)
+		if err != nil {
+			return nil, err
+		}
+		// no row must have the values of the index
+		wait := ovsdb.Operation{
+			Op:      opWait,
+			Table:   table,
+			Where:   where,
+			Columns: index,
+			Until:   string(ovsdb.WaitConditionEqual),
+			Rows:    []map[string]interface{}{},
+		}
+		return append([]ovsdb.Operation{wait}, insert...), nil
+	}
+
+	row, err := a.cache.orm.newRow(table, model, fields...)
+	if err != nil {
+		return nil, err
+	}
+	// the row already has the values of the index, which may not be mutable
+	for _, column := range index {
+		delete(row, column)
+	}
+	if _, err := a.removeImmutable(table, row); err != nil {
+		return nil, err
+	}
+	if len(row) == 0 {
+		return []ovsdb.Operation{}, nil
+	}
+	byUUID := []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: uuid})}
+	return []ovsdb.Operation{
+		{
+			Op:      opWait,
+			Table:   table,
+			Where:   byUUID,
+			Columns: index,
+			Until:   string(ovsdb.WaitConditionEqual),
+			Rows:    []map[string]interface{}{indexRow},
+		},
+		{
+			Op:    opUpdate,
+			Table: table,
+			Row:   row,
+			Where: byUUID,
+		},
+	}, nil
+}
+
+// upsertIndex returns the first index of the schema for which the model has values
+func upsertIndex(info *ormInfo) ([]string, error) {
+	indexes, err := info.getValidORMIndexes()
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range indexes {
+		if len(index) == 1 && index[0] == "_uuid" {
+			continue
+		}
+		return append([]string{}, index...), nil
+	}
+	return nil, fmt.Errorf("the model has no value for any index of the table")
+}
+
+// cachedRowByIndex returns the UUID of the cached row with the values of the model
+// in the columns of the index or an empty string if there is none
+func (a api) cachedRowByIndex(table string, info *ormInfo, index []string) (string, error) {
+	tableCache := a.cache.Table(table)
+	if tableCache == nil {
+		return "", nil
+	}
+	candidates, indexed, err := tableCache.rowsByModel(info.obj, false)
+	if err != nil {
+		return "", err
+	}
+	if !indexed {
+		candidates = tableCache.Rows()
+	}
+	for _, uuid := range candidates {
+		cached := tableCache.Row(uuid)
+		if cached == nil {
+			continue
+		}
+		cachedInfo, err := newORMInfo(info.table, cached)
+		if err != nil {
+			return "", err
+		}
+		equal := true
+		for _, column := range index {
+			value, err := info.fieldByColumn(column)
+			if err != nil {
+				return "", err
+			}
+			cachedValue, err := cachedInfo.fieldByColumn(column)
+			if err != nil {
+				return "", err
+			}
+			if !nativeEqual(value, cachedValue) {
+				equal = false
+				break
+			}
+		}
+		if equal {
+			return uuid, nil
+		}
+	}
+	return "", nil
+}
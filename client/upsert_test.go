@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/rpc2"
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIUpsert(t *testing.T) {
+	cache := apiTestCache(t)
+	cache.cache["Logical_Switch_Port"] = &RowCache{cache: map[string]Model{
+		aUUID0: &testLogicalSwitchPort{UUID: aUUID0, Name: "lsp0", Type: "someType"},
+	}}
+	a := newAPI(cache)
+	byUUID := []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: aUUID0})}
+	existingWait := ovsdb.Operation{
+		Op:      opWait,
+		Table:   "Logical_Switch_Port",
+		Where:   byUUID,
+		Columns: []string{"name"},
+		Until:   "==",
+		Rows:    []map[string]interface{}{{"name": "lsp0"}},
+	}
+
+	// the row is not in the cache, so it is inserted unless another one with its name was created
+	created := testLogicalSwitchPort{Name: "lsp1", Type: "otherType"}
+	ops, err := a.Upsert(&created)
+	require.Nil(t, err)
+	assert.Equal(t, []ovsdb.Operation{
+		{
+			Op:      opWait,
+			Table:   "Logical_Switch_Port",
+			Where:   []ovsdb.Condition{ovsdb.NewCondition("name", ovsdb.ConditionEqual, "lsp1")},
+			Columns: []string{"name"},
+			Until:   "==",
+			Rows:    []map[string]interface{}{},
+		},
+		{
+			Op:    opInsert,
+			Table: "Logical_Switch_Port",
+			Row:   map[string]interface{}{"name": "lsp1", "type": "otherType"},
+		},
+	}, ops)
+
+	// the row with the same name is updated unless it was deleted or renamed
+	updated := testLogicalSwitchPort{Name: "lsp0", Type: "otherType", ExternalIds: map[string]string{"foo": "bar"}}
+	ops, err = a.Upsert(&updated)
+	require.Nil(t, err)
+	assert.Equal(t, []ovsdb.Operation{
+		existingWait,
+		{
+			Op:    opUpdate,
+			Table: "Logical_Switch_Port",
+			Row:   map[string]interface{}{"type": "otherType", "external_ids": testOvsMap(t, map[string]string{"foo": "bar"})},
+			Where: byUUID,
+		},
+	}, ops)
+
+	ops, err = a.Upsert(&updated, &updated.Type)
+	require.Nil(t, err)
+	require.Len(t, ops, 2)
+	assert.Equal(t, existingWait, ops[0])
+	assert.Equal(t, map[string]interface{}{"type": "otherType"}, ops[1].Row)
+
+	// there is nothing to update
+	ops, err = a.Upsert(&updated, &updated.Name)
+	require.Nil(t, err)
+	assert.Empty(t, ops)
+
+	_, err = a.Upsert(&testLogicalSwitchPort{UUID: aUUID0, Type: "otherType"})
+	assert.NotNil(t, err, "the model has no value for the index")
+	_, err = a.Upsert(&updated, &created.Type)
+	assert.NotNil(t, err)
+	_, err = a.Upsert(&struct{ Name string }{})
+	assert.NotNil(t, err)
+}
+
+func TestUpsertRetryTransaction(t *testing.T) {
+	const br0 = "2f77b348-9768-4866-b761-89d5177ecda0"
+	server := newTestOvsdbServer(t)
+	var transactions [][]interface{}
+	results := [][]interface{}{
+		{map[string]interface{}{"error": "timed out"}, nil},
+		{map[string]interface{}{}, map[string]interface{}{"count": 1}},
+	}
+	server.handlers["transact"] = func(_ *rpc2.Client, args []interface{}, reply *[]interface{}) error {
+		i := len(transactions)
+		transactions = append(transactions, args[1:])
+		*reply = results[i]
+		if i == 0 {
+			// someone else created the bridge
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				server.notify("update", "all", map[string]interface{}{"Bridge": map[string]interface{}{br0: map[string]interface{}{"new": map[string]interface{}{
+					"name": "br0",
+				}}}})
+			}()
+		}
+		return nil
+	}
+	server.start()
+
+	ovs, err := NewOvsdbClient(defDB, WithEndpoint(server.endpoint()))
+	require.Nil(t, err)
+	require.Nil(t, ovs.Connect(context.Background()))
+	defer ovs.Disconnect()
+	require.Nil(t, ovs.MonitorAll(context.Background(), "all"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = ovs.RetryTransaction(ctx, func(txn *Transaction) error {
+		return txn.Add(ovs.Upsert(&bridgeType{Name: "br0", ExternalIds: map[string]string{"owner": "me"}}))
+	})
+	require.Nil(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, opInsert, transactions[0][1].(map[string]interface{})["op"])
+	assert.Equal(t, opUpdate, transactions[1][1].(map[string]interface{})["op"])
+}
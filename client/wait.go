@@ -0,0 +1,261 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// defaultTransactionRetries is the number of times RetryTransaction runs a transaction again by default
+const defaultTransactionRetries = 3
+
+// waitCondition holds the values of the columns of a row when it was read, which a
+// wait operation requires the row to still have
+type waitCondition struct {
+	table   string
+	columns []string
+	row     map[string]interface{}
+}
+
+// WhereUnchanged returns a ConditionalAPI based on the index data of a model, as Where does,
+// whose Update, UpdateDiff and Mutate operations are preceded by a wait operation that fails
+// the transaction, with an ovsdb.TimedOut error, if the row no longer has the values of the
+// model. The model, usually a copy of the cached one, is copied so that it can be modified
+// afterwards and the wait is restricted to the columns of the optional fields (pointers
+// to fields in the model). If no field is given, every column of the model is compared
+// so they must all be monitored
+func (a api) WhereUnchanged(model Model, fields ...interface{}) ConditionalAPI {
+	table, err := a.getTableFromModel(model)
+	if err != nil {
+		return newConditionalAPI(a, newErrorConditional(err))
+	}
+	wait, err := a.newWaitCondition(table, model, fields...)
+	if err != nil {
+		return newConditionalAPI(a, newErrorConditional(err))
+	}
+	return api{
+		cache:         a.cache,
+		cond:          a.conditionFromModel(false, copyModel(model)),
+		transact:      a.transact,
+		wait:          wait,
+		skipImmutable: a.skipImmutable,
+	}
+}
+
+// newWaitCondition returns the waitCondition of the columns of the given fields of the
+// model or of all its columns but "_uuid" if no field is given
+func (a api) newWaitCondition(table string, model Model, fields ...interface{}) (*waitCondition, error) {
+	tableSchema := a.cache.orm.schema.Table(table)
+	if tableSchema == nil {
+		return nil, NewErrNoTable(table)
+	}
+	info, err := newORMInfo(tableSchema, model)
+	if err != nil {
+		return nil, err
+	}
+	var columns []string
+	if len(fields) > 0 {
+		for _, field := range fields {
+			column, err := info.columnByPtr(field)
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, column)
+		}
+	} else {
+		for column := range tableSchema.Columns {
+			if info.hasColumn(column) {
+				columns = append(columns, column)
+			}
+		}
+	}
+
+	wait := &waitCondition{table: table, row: make(map[string]interface{}, len(columns))}
+	for _, column := range columns {
+		columnSchema := tableSchema.Column(column)
+		if columnSchema == nil || column == "_uuid" {
+			continue
+		}
+		value, err := info.fieldByColumn(column)
+		if err != nil {
+			return nil, err
+		}
+		ovsValue, err := ovsdb.NativeToOvs(columnSchema, value)
+		if err != nil {
+			return nil, fmt.Errorf("table %s, column %s: failed to generate ovs element. %s", table, column, err.Error())
+		}
+		wait.columns = append(wait.columns, column)
+		wait.row[column] = ovsValue
+	}
+	if len(wait.columns) == 0 {
+		return nil, fmt.Errorf("no column to wait for in table %s", table)
+	}
+	return wait, nil
+}
+
+// waitOperations returns the wait operation, if any, that must precede the
+// operations on the rows that match the condition
+func (a api) waitOperations(condition []ovsdb.Condition) []ovsdb.Operation {
+	if a.wait == nil {
+		return nil
+	}
+	return []ovsdb.Operation{{
+		Op:      opWait,
+		Table:   a.wait.table,
+		Where:   condition,
+		Columns: a.wait.columns,
+		Until:   "==",
+		Rows:    []map[string]interface{}{a.wait.row},
+		Timeout: 0,
+	}}
+}
+
+// RetryTransaction runs fn, which is expected to read from the cache and add to the given
+// Transaction the operations to write, and commits the transaction. If a wait operation of
+// the transaction, such as the ones added by WhereUnchanged, fails because the rows changed
+// since they were read, fn is run again with a new Transaction once the cache reflects the
+// changes, up to the number of retries set with WithTransactionRetries. The context bounds
+// both the transactions and the waits for the cache. The error of the last attempt is returned
+func (ovs *OvsdbClient) RetryTransaction(ctx context.Context, fn func(*Transaction) error) (map[string]string, error) {
+	for attempt := 0; ; attempt++ {
+		txn := ovs.NewTransaction()
+		if err := fn(txn); err != nil {
+			return nil, err
+		}
+		uuids, err := txn.Commit(ctx)
+		waits := failedWaits(err)
+		if len(waits) == 0 || attempt >= ovs.options.transactionRetries {
+			return uuids, err
+		}
+		if err := ovs.Cache.waitFor(ctx, ovs.waitsOutdated(waits)); err != nil {
+			return nil, fmt.Errorf("waiting for the cache to reflect the changes: %w", err)
+		}
+	}
+}
+
+// failedWaits returns the wait operations that made a transaction fail
+func failedWaits(err error) []*ovsdb.Operation {
+	var txnErr *TransactionError
+	if !errors.As(err, &txnErr) {
+		return nil
+	}
+	var waits []*ovsdb.Operation
+	for _, opErr := range txnErr.Errors {
+		if timedOut, ok := opErr.(*ovsdb.TimedOut); ok && timedOut.Operation() != nil && timedOut.Operation().Op == opWait {
+			waits = append(waits, timedOut.Operation())
+		}
+	}
+	return waits
+}
+
+// waitsOutdated returns a function that tells whether the cache no longer has the rows that
+// the wait operations expected, which have been changed by someone else. Only the rows
+// identified by their UUID and the monitored columns are tracked, along with the waits
+// for no row to match, such as the ones of Upsert. If none is, the function returns true
+// right away
+func (ovs *OvsdbClient) waitsOutdated(waits []*ovsdb.Operation) func() (bool, error) {
+	monitored := ovs.monitoredColumns()
+	var checks []func() (bool, error)
+	for _, op := range waits {
+		tableColumns, ok := monitored[op.Table]
+		if ok && len(op.Rows) == 0 && columnsMonitored(tableColumns, op.Where) {
+			table, where := op.Table, op.Where
+			checks = append(checks, func() (bool, error) {
+				return ovs.cachedRowMatches(table, where)
+			})
+			continue
+		}
+		uuid := whereUUID(op.Where)
+		if !ok || uuid == "" || len(op.Rows) != 1 {
+			continue
+		}
+		columns := make(map[string]bool)
+		for _, column := range op.Columns {
+			if tableColumns == nil || tableColumns[column] {
+				columns[column] = true
+			}
+		}
+		if len(columns) == 0 {
+			continue
+		}
+		table, row := op.Table, op.Rows[0]
+		checks = append(checks, func() (bool, error) {
+			model := ovs.cachedRow(table, uuid)
+			if model == nil {
+				return true, nil
+			}
+			equal, err := ovs.Cache.orm.rowEqual(table, model, row, columns)
+			return !equal, err
+		})
+	}
+	return func() (bool, error) {
+		if len(checks) == 0 {
+			return true, nil
+		}
+		for _, check := range checks {
+			if outdated, err := check(); err != nil || outdated {
+				return outdated, err
+			}
+		}
+		return false, nil
+	}
+}
+
+// columnsMonitored tells whether the columns of the conditions are all monitored
+func columnsMonitored(tableColumns map[string]bool, where []ovsdb.Condition) bool {
+	if tableColumns == nil {
+		return true
+	}
+	for _, cond := range where {
+		if cond.Column != "_uuid" && !tableColumns[cond.Column] {
+			return false
+		}
+	}
+	return true
+}
+
+// cachedRowMatches tells whether any cached row of the table matches all the conditions
+func (ovs *OvsdbClient) cachedRowMatches(table string, where []ovsdb.Condition) (bool, error) {
+	tableCache := ovs.Cache.Table(table)
+	tableSchema := ovs.Cache.orm.schema.Table(table)
+	if tableCache == nil || tableSchema == nil {
+		return false, nil
+	}
+	for _, uuid := range tableCache.Rows() {
+		model := tableCache.Row(uuid)
+		if model == nil {
+			continue
+		}
+		info, err := newORMInfo(tableSchema, model)
+		if err != nil {
+			return false, err
+		}
+		matches := true
+		for _, cond := range where {
+			column := tableSchema.Column(cond.Column)
+			if column == nil || !info.hasColumn(cond.Column) {
+				return false, fmt.Errorf("column %s not found", cond.Column)
+			}
+			value, err := ovsdb.OvsToNative(column, cond.Value)
+			if err != nil {
+				return false, err
+			}
+			actual, err := info.fieldByColumn(cond.Column)
+			if err != nil {
+				return false, err
+			}
+			if matches, err = ovsdb.EvaluateCondition(column, cond.Function, actual, value); err != nil {
+				return false, err
+			}
+			if !matches {
+				break
+			}
+		}
+		if matches {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,171 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/rpc2"
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIWhereUnchanged(t *testing.T) {
+	cache := apiTestCache(t)
+	a := newAPI(cache)
+	where := []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: aUUID0})}
+	lsp := testLogicalSwitchPort{
+		UUID:        aUUID0,
+		Name:        "lsp0",
+		Type:        "someType",
+		ExternalIds: map[string]string{"foo": "bar"},
+	}
+	read := lsp
+	unchanged := a.WhereUnchanged(&lsp, &lsp.Type, &lsp.ExternalIds)
+	// the values compared are the ones of the model when WhereUnchanged was called
+	lsp.Type = "otherType"
+	lsp.ExternalIds = map[string]string{"foo": "baz"}
+
+	wait := ovsdb.Operation{
+		Op:      opWait,
+		Table:   "Logical_Switch_Port",
+		Where:   where,
+		Columns: []string{"type", "external_ids"},
+		Until:   "==",
+		Rows: []map[string]interface{}{{
+			"type":         "someType",
+			"external_ids": testOvsMap(t, map[string]string{"foo": "bar"}),
+		}},
+	}
+
+	ops, err := unchanged.Update(&lsp, &lsp.Type)
+	require.Nil(t, err)
+	require.Len(t, ops, 2)
+	assert.Equal(t, wait, ops[0])
+	assert.Equal(t, opUpdate, ops[1].Op)
+	assert.Equal(t, where, ops[1].Where)
+
+	ops, err = unchanged.Mutate(&lsp, []Mutation{{Field: &lsp.ExternalIds, Mutator: ovsdb.MutateOperationInsert, Value: map[string]string{"new": "key"}}})
+	require.Nil(t, err)
+	require.Len(t, ops, 2)
+	assert.Equal(t, wait, ops[0])
+	assert.Equal(t, opMutate, ops[1].Op)
+
+	ops, err = unchanged.UpdateDiff(&read, &lsp)
+	require.Nil(t, err)
+	require.Len(t, ops, 3)
+	assert.Equal(t, wait, ops[0])
+
+	// nothing to wait for if there is nothing to write
+	ops, err = unchanged.UpdateDiff(&read, &read)
+	require.Nil(t, err)
+	assert.Empty(t, ops)
+
+	ops, err = unchanged.Delete()
+	require.Nil(t, err)
+	require.Len(t, ops, 1)
+	assert.Equal(t, opDelete, ops[0].Op)
+
+	// every column of the model but _uuid is compared by default
+	ops, err = a.WhereUnchanged(&read).Update(&read)
+	require.Nil(t, err)
+	require.Len(t, ops, 2)
+	assert.Len(t, ops[0].Columns, 15)
+	assert.NotContains(t, ops[0].Columns, "_uuid")
+	assert.Equal(t, "lsp0", ops[0].Rows[0]["name"])
+
+	_, err = a.WhereUnchanged(&testLogicalSwitchPort{}).Update(&read)
+	assert.NotNil(t, err)
+	_, err = a.WhereUnchanged(&read, &lsp.Name).Update(&read)
+	assert.NotNil(t, err)
+}
+
+func TestRetryTransaction(t *testing.T) {
+	const br0 = "2f77b348-9768-4866-b761-89d5177ecda0"
+	server := newTestOvsdbServer(t)
+	server.setRow("Bridge", br0, map[string]interface{}{
+		"name":         "br0",
+		"external_ids": []interface{}{"map", []interface{}{[]interface{}{"owner", "a"}}},
+	})
+	// results[i] is the reply to the ith transaction, which is followed by the update, if any
+	var results [][]interface{}
+	var updates []map[string]interface{}
+	var transactions [][]interface{}
+	server.handlers["transact"] = func(_ *rpc2.Client, args []interface{}, reply *[]interface{}) error {
+		i := len(transactions)
+		transactions = append(transactions, args[1:])
+		*reply = results[i]
+		if i < len(updates) && updates[i] != nil {
+			update := updates[i]
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				server.notify("update", "all", update)
+			}()
+		}
+		return nil
+	}
+	server.start()
+
+	ovs, err := NewOvsdbClient(defDB, WithEndpoint(server.endpoint()), WithTransactionRetries(1))
+	require.Nil(t, err)
+	require.Nil(t, ovs.Connect(context.Background()))
+	defer ovs.Disconnect()
+	require.Nil(t, ovs.MonitorAll(context.Background(), "all"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var read []map[string]string
+	setOwner := func(txn *Transaction) error {
+		bridge := &bridgeType{UUID: br0}
+		if err := ovs.Get(bridge); err != nil {
+			return err
+		}
+		read = append(read, bridge.ExternalIds)
+		updated := copyModel(bridge).(*bridgeType)
+		updated.ExternalIds["owner"] = "b"
+		return txn.Add(ovs.WhereUnchanged(bridge, &bridge.ExternalIds).UpdateDiff(bridge, updated))
+	}
+	timedOut := []interface{}{map[string]interface{}{"error": "timed out"}, nil}
+	succeeded := []interface{}{map[string]interface{}{}, map[string]interface{}{"count": 1}}
+
+	// the row changed after it was read, so the transaction is run again once the cache has the change
+	setOwnerUpdate := func(owner string) map[string]interface{} {
+		return map[string]interface{}{"Bridge": map[string]interface{}{br0: map[string]interface{}{"new": map[string]interface{}{
+			"name":         "br0",
+			"external_ids": []interface{}{"map", []interface{}{[]interface{}{"owner", owner}}},
+		}}}}
+	}
+	results = [][]interface{}{timedOut, succeeded}
+	updates = []map[string]interface{}{setOwnerUpdate("c")}
+	_, err = ovs.RetryTransaction(ctx, setOwner)
+	require.Nil(t, err)
+	assert.Equal(t, []map[string]string{{"owner": "a"}, {"owner": "c"}}, read)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, opWait, transactions[1][0].(map[string]interface{})["op"])
+
+	// the number of retries is limited
+	transactions, read = nil, nil
+	results = [][]interface{}{timedOut, timedOut}
+	updates = []map[string]interface{}{setOwnerUpdate("d")}
+	_, err = ovs.RetryTransaction(ctx, setOwner)
+	var txnErr *TransactionError
+	require.True(t, errors.As(err, &txnErr))
+	require.Len(t, txnErr.Errors, 1)
+	assert.IsType(t, &ovsdb.TimedOut{}, txnErr.Errors[0])
+	assert.Len(t, transactions, 2)
+
+	// other errors are not retried
+	transactions, updates = nil, nil
+	results = [][]interface{}{{map[string]interface{}{}, map[string]interface{}{"error": "constraint violation"}}}
+	_, err = ovs.RetryTransaction(ctx, setOwner)
+	assert.NotNil(t, err)
+	assert.Len(t, transactions, 1)
+
+	transactions = nil
+	fnErr := errors.New("fn failed")
+	_, err = ovs.RetryTransaction(ctx, func(*Transaction) error { return fnErr })
+	assert.True(t, errors.Is(err, fnErr))
+	assert.Empty(t, transactions)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -9,7 +10,6 @@ import (
 	"runtime/pprof"
 
 	"github.com/ovn-org/libovsdb/client"
-	"github.com/ovn-org/libovsdb/ovsdb"
 )
 
 // ORMBridge is the simplified ORM model of the Bridge table
@@ -42,7 +42,7 @@ var (
 )
 
 func run() {
-	ovs, err := client.Connect(*connection, dbModel, nil)
+	ovs, err := client.Connect(context.Background(), *connection, dbModel, nil)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -58,7 +58,7 @@ func run() {
 		},
 	)
 
-	if err := ovs.MonitorAll(""); err != nil {
+	if err := ovs.MonitorAll(context.Background(), ""); err != nil {
 		log.Fatal(err)
 	}
 
@@ -84,43 +84,27 @@ func run() {
 	}
 }
 
-func transact(ovs *client.OvsdbClient, operations []ovsdb.Operation) (ok bool, uuid string) {
-	reply, err := ovs.Transact(operations...)
-	if err != nil {
-		ok = false
-		return
-	}
-	if _, err := ovsdb.CheckOperationResults(reply, operations); err != nil {
-		ok = false
-		return
-	}
-	uuid = reply[0].UUID.GoUUID
-	return
-}
-
 func deleteBridge(ovs *client.OvsdbClient, bridge *ormBridge) {
-	deleteOp, err := ovs.Where(bridge).Delete()
-	if err != nil {
+	txn := ovs.NewTransaction()
+	if err := txn.Add(ovs.Where(bridge).Delete()); err != nil {
 		log.Fatal(err)
 	}
 	ovsRow := ormOvs{
 		UUID: rootUUID,
 	}
 
-	mutateOp, err := ovs.Where(&ovsRow).Mutate(&ovsRow, []client.Mutation{
+	err := txn.Add(ovs.Where(&ovsRow).Mutate(&ovsRow, []client.Mutation{
 		{
 			Field:   &ovsRow.Bridges,
 			Mutator: "delete",
 			Value:   []string{bridge.UUID},
 		},
-	})
+	}))
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	operations := append(deleteOp, mutateOp...)
-	ok, _ := transact(ovs, operations)
-	if ok {
+	if _, err := txn.Commit(context.Background()); err == nil {
 		if *verbose {
 			fmt.Println("Bridge Deletion Successful : ", bridge.UUID)
 		}
@@ -140,27 +124,25 @@ func createBridge(ovs *client.OvsdbClient, iter int) {
 			"key2": "val2",
 		},
 	}
-	insertOp, err := ovs.Create(&bridge)
-	if err != nil {
+	txn := ovs.NewTransaction()
+	if err := txn.Create(&bridge); err != nil {
 		log.Fatal(err)
 	}
 	ovsRow := ormOvs{}
-	mutateOp, err := ovs.Where(&ormOvs{UUID: rootUUID}).Mutate(&ovsRow, []client.Mutation{
+	err := txn.Add(ovs.Where(&ormOvs{UUID: rootUUID}).Mutate(&ovsRow, []client.Mutation{
 		{
 			Field:   &ovsRow.Bridges,
 			Mutator: "insert",
 			Value:   []string{bridge.UUID},
 		},
-	})
+	}))
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	operations := append(insertOp, mutateOp...)
-	ok, uuid := transact(ovs, operations)
-	if ok {
+	if _, err := txn.Commit(context.Background()); err == nil {
 		if *verbose {
-			fmt.Println("Bridge Addition Successful : ", uuid)
+			fmt.Println("Bridge Addition Successful : ", bridge.UUID)
 		}
 	}
 }
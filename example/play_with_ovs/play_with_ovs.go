@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -83,7 +84,7 @@ func createBridge(ovs *client.OvsdbClient, bridgeName string) {
 	}
 
 	operations := append(insertOp, mutateOps...)
-	reply, err := ovs.Transact(operations...)
+	reply, err := ovs.Transact(context.Background(), operations...)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -116,7 +117,7 @@ func main() {
 		log.Fatal("Unable to create DB model ", err)
 	}
 	// By default libovsdb connects to 127.0.0.0:6400.
-	ovs, err := client.Connect(*connection, dbmodel, nil)
+	ovs, err := client.Connect(context.Background(), *connection, dbmodel, nil)
 
 	// If you prefer to connect to OVS in a specific location :
 	// ovs, err := client.Connect("tcp:192.168.56.101:6640", nil)
@@ -133,7 +134,7 @@ func main() {
 		},
 	})
 
-	err = ovs.MonitorAll("")
+	err = ovs.MonitorAll(context.Background(), "")
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -116,6 +116,10 @@ func OvsToNative(column *ColumnSchema, ovsElem interface{}) (interface{}, error)
 		// We need to convert it to the real type os slice
 		var nativeSet reflect.Value
 
+		// NativeToOvs returns a pointer
+		if ovsSet, ok := ovsElem.(*OvsSet); ok {
+			ovsElem = *ovsSet
+		}
 		// RFC says that for a set of exactly one, an atomic type an be sent
 		switch ovsSet := ovsElem.(type) {
 		case OvsSet:
@@ -141,6 +145,9 @@ func OvsToNative(column *ColumnSchema, ovsElem interface{}) (interface{}, error)
 
 	case TypeMap:
 		naType := NativeType(column)
+		if ovsMap, ok := ovsElem.(*OvsMap); ok {
+			ovsElem = *ovsMap
+		}
 		ovsMap, ok := ovsElem.(OvsMap)
 		if !ok {
 			return nil, NewErrWrongType("OvsToNative", "OvsMap", ovsElem)
@@ -300,7 +307,12 @@ func ValidateCondition(column *ColumnSchema, function ConditionFunction, nativeV
 			NativeType(column).String(), nativeValue)
 	}
 
-	switch column.Type {
+	columnType := column.Type
+	if columnType == TypeEnum {
+		// enums are compared as values of the type of their key
+		columnType = column.TypeObj.Key.Type
+	}
+	switch columnType {
 	case TypeSet, TypeMap, TypeBoolean, TypeString, TypeUUID:
 		switch function {
 		case ConditionEqual, ConditionNotEqual, ConditionIncludes, ConditionExcludes:
@@ -493,6 +493,34 @@ func TestNativeToOvs(t *testing.T) {
 	}
 }
 
+func TestNativeToOvsRoundTrip(t *testing.T) {
+	transMaps := getTransMaps()
+	for _, trans := range transMaps {
+		t.Run(fmt.Sprintf("Native To Ovs and back: %s", trans["name"]), func(t *testing.T) {
+			var column ColumnSchema
+			if err := json.Unmarshal(trans["schema"].([]byte), &column); err != nil {
+				t.Fatal(err)
+			}
+
+			ovs, err := NativeToOvs(&column, trans["native"])
+			if err != nil {
+				t.Fatalf("failed to convert %s: %s", trans, err)
+			}
+			res, err := OvsToNative(&column, ovs)
+			if err != nil {
+				t.Fatalf("failed to convert %v back: %s", ovs, err)
+			}
+
+			if !reflect.DeepEqual(res, trans["ovs2native"]) {
+				t.Errorf("fail to convert native2ovs2native. native: %v(%s). expected %v(%s). got %v (%s)",
+					trans["native"], reflect.TypeOf(trans["native"]),
+					trans["ovs2native"], reflect.TypeOf(trans["ovs2native"]),
+					res, reflect.TypeOf(res))
+			}
+		})
+	}
+}
+
 func TestOvsToNativeErr(t *testing.T) {
 	transMaps := getErrTransMaps()
 	for _, trans := range transMaps {
@@ -1047,6 +1075,20 @@ func TestConditionValidation(t *testing.T) {
 			value:     map[string]int{"foo": 42},
 			valid:     false,
 		},
+		{
+			name:      "enum",
+			column:    []byte(`{"type": {"key": {"type": "string", "enum": ["set", ["foo", "bar"]]}}}`),
+			functions: []ConditionFunction{ConditionEqual, ConditionIncludes, ConditionNotEqual, ConditionExcludes},
+			value:     "foo",
+			valid:     true,
+		},
+		{
+			name:      "enum inequality",
+			column:    []byte(`{"type": {"key": {"type": "string", "enum": ["set", ["foo", "bar"]]}}}`),
+			functions: []ConditionFunction{ConditionGreaterThanOrEqual, ConditionGreaterThan, ConditionLessThan, ConditionLessThanOrEqual},
+			value:     "foo",
+			valid:     false,
+		},
 	}
 	for _, test := range tests {
 		t.Run(fmt.Sprintf("ConditionValidation: %s", test.name), func(t *testing.T) {
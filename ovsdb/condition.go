@@ -3,6 +3,7 @@ package ovsdb
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 )
 
 type ConditionFunction string
@@ -68,3 +69,99 @@ func (c Condition) UnmarshalJSON(b []byte) error {
 	c.Value = v[2]
 	return nil
 }
+
+// EvaluateCondition returns whether the native value of a column satisfies a condition with the
+// given function and native value, as defined in RFC 7047 section 5.1:
+// the inequalities compare integers and reals, "==" and "!=" compare the whole value, sets are
+// equal regardless of the order of their elements, and "includes" and "excludes" behave as "=="
+// and "!=" for atomic values while, for sets and maps, they check whether the column contains all
+// or none of the elements or key-value pairs of the value
+func EvaluateCondition(column *ColumnSchema, function ConditionFunction, actual, value interface{}) (bool, error) {
+	if err := ValidateCondition(column, function, value); err != nil {
+		return false, err
+	}
+	actualValue, condValue := reflect.ValueOf(actual), reflect.ValueOf(value)
+	if !actualValue.IsValid() || actualValue.Type() != condValue.Type() {
+		return false, NewErrWrongType("EvaluateCondition", condValue.Type().String(), actual)
+	}
+	switch column.Type {
+	case TypeSet:
+		switch function {
+		case ConditionEqual, ConditionNotEqual:
+			equal := actualValue.Len() == condValue.Len() && countElements(actualValue, condValue) == condValue.Len()
+			return equal == (function == ConditionEqual), nil
+		case ConditionIncludes:
+			return countElements(actualValue, condValue) == condValue.Len(), nil
+		default:
+			return countElements(actualValue, condValue) == 0, nil
+		}
+	case TypeMap:
+		switch function {
+		case ConditionEqual, ConditionNotEqual:
+			equal := actualValue.Len() == condValue.Len() && countPairs(actualValue, condValue) == condValue.Len()
+			return equal == (function == ConditionEqual), nil
+		case ConditionIncludes:
+			return countPairs(actualValue, condValue) == condValue.Len(), nil
+		default:
+			return countPairs(actualValue, condValue) == 0, nil
+		}
+	}
+	switch actualValue.Kind() {
+	case reflect.Int:
+		return compare(function, float64(actualValue.Int()), float64(condValue.Int())), nil
+	case reflect.Float64:
+		return compare(function, actualValue.Float(), condValue.Float()), nil
+	default:
+		equal := actual == value
+		switch function {
+		case ConditionEqual, ConditionIncludes:
+			return equal, nil
+		default:
+			return !equal, nil
+		}
+	}
+}
+
+// compare applies a condition function to two numbers
+func compare(function ConditionFunction, a, b float64) bool {
+	switch function {
+	case ConditionLessThan:
+		return a < b
+	case ConditionLessThanOrEqual:
+		return a <= b
+	case ConditionGreaterThan:
+		return a > b
+	case ConditionGreaterThanOrEqual:
+		return a >= b
+	case ConditionNotEqual, ConditionExcludes:
+		return a != b
+	default:
+		return a == b
+	}
+}
+
+// countElements returns how many elements of the slice elems are in the slice set
+func countElements(set, elems reflect.Value) int {
+	count := 0
+	for i := 0; i < elems.Len(); i++ {
+		for j := 0; j < set.Len(); j++ {
+			if set.Index(j).Interface() == elems.Index(i).Interface() {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// countPairs returns how many key-value pairs of the map pairs are in the map m
+func countPairs(m, pairs reflect.Value) int {
+	count := 0
+	iter := pairs.MapRange()
+	for iter.Next() {
+		if v := m.MapIndex(iter.Key()); v.IsValid() && v.Interface() == iter.Value().Interface() {
+			count++
+		}
+	}
+	return count
+}
@@ -1,6 +1,7 @@
 package ovsdb
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -131,3 +132,142 @@ func TestCondition_UnmarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestEvaluateCondition(t *testing.T) {
+	all := []ConditionFunction{ConditionLessThan, ConditionLessThanOrEqual, ConditionEqual, ConditionNotEqual,
+		ConditionGreaterThan, ConditionGreaterThanOrEqual, ConditionIncludes, ConditionExcludes}
+	tests := []struct {
+		name   string
+		column string
+		actual interface{}
+		value  interface{}
+		// matching holds the functions that evaluate to true, the rest evaluate to false
+		matching []ConditionFunction
+	}{
+		{
+			name:     "integer equal",
+			column:   `{"type": "integer"}`,
+			actual:   42,
+			value:    42,
+			matching: []ConditionFunction{ConditionLessThanOrEqual, ConditionEqual, ConditionGreaterThanOrEqual, ConditionIncludes},
+		},
+		{
+			name:     "integer less",
+			column:   `{"type": "integer"}`,
+			actual:   1,
+			value:    42,
+			matching: []ConditionFunction{ConditionLessThan, ConditionLessThanOrEqual, ConditionNotEqual, ConditionExcludes},
+		},
+		{
+			name:     "real greater",
+			column:   `{"type": "real"}`,
+			actual:   4.2,
+			value:    0.5,
+			matching: []ConditionFunction{ConditionGreaterThan, ConditionGreaterThanOrEqual, ConditionNotEqual, ConditionExcludes},
+		},
+		{
+			name:     "string",
+			column:   `{"type": "string"}`,
+			actual:   "foo",
+			value:    "foo",
+			matching: []ConditionFunction{ConditionEqual, ConditionIncludes},
+		},
+		{
+			name:     "boolean",
+			column:   `{"type": "boolean"}`,
+			actual:   true,
+			value:    false,
+			matching: []ConditionFunction{ConditionNotEqual, ConditionExcludes},
+		},
+		{
+			name:     "enum",
+			column:   `{"type": {"key": {"type": "string", "enum": ["set", ["foo", "bar"]]}}}`,
+			actual:   "foo",
+			value:    "bar",
+			matching: []ConditionFunction{ConditionNotEqual, ConditionExcludes},
+		},
+		{
+			name:     "set equal in another order",
+			column:   `{"type": {"key": "string", "min": 0, "max": "unlimited"}}`,
+			actual:   []string{"foo", "bar"},
+			value:    []string{"bar", "foo"},
+			matching: []ConditionFunction{ConditionEqual, ConditionIncludes},
+		},
+		{
+			name:     "set includes",
+			column:   `{"type": {"key": "string", "min": 0, "max": "unlimited"}}`,
+			actual:   []string{"foo", "bar"},
+			value:    []string{"bar"},
+			matching: []ConditionFunction{ConditionNotEqual, ConditionIncludes},
+		},
+		{
+			name:     "set partially included",
+			column:   `{"type": {"key": "string", "min": 0, "max": "unlimited"}}`,
+			actual:   []string{"foo", "bar"},
+			value:    []string{"bar", "baz"},
+			matching: []ConditionFunction{ConditionNotEqual},
+		},
+		{
+			name:     "set excludes",
+			column:   `{"type": {"key": "string", "min": 0, "max": "unlimited"}}`,
+			actual:   []string{"foo"},
+			value:    []string{"bar", "baz"},
+			matching: []ConditionFunction{ConditionNotEqual, ConditionExcludes},
+		},
+		{
+			name:     "empty set",
+			column:   `{"type": {"key": "string", "min": 0, "max": "unlimited"}}`,
+			actual:   []string{},
+			value:    []string{},
+			matching: []ConditionFunction{ConditionEqual, ConditionIncludes, ConditionExcludes},
+		},
+		{
+			name:     "map equal",
+			column:   `{"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}}`,
+			actual:   map[string]string{"foo": "bar", "baz": "quux"},
+			value:    map[string]string{"foo": "bar", "baz": "quux"},
+			matching: []ConditionFunction{ConditionEqual, ConditionIncludes},
+		},
+		{
+			name:     "map includes",
+			column:   `{"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}}`,
+			actual:   map[string]string{"foo": "bar", "baz": "quux"},
+			value:    map[string]string{"foo": "bar"},
+			matching: []ConditionFunction{ConditionNotEqual, ConditionIncludes},
+		},
+		{
+			name:     "map with another value",
+			column:   `{"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}}`,
+			actual:   map[string]string{"foo": "bar"},
+			value:    map[string]string{"foo": "baz"},
+			matching: []ConditionFunction{ConditionNotEqual, ConditionExcludes},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var column ColumnSchema
+			assert.Nil(t, json.Unmarshal([]byte(tt.column), &column))
+			for _, function := range all {
+				if ValidateCondition(&column, function, tt.value) != nil {
+					_, err := EvaluateCondition(&column, function, tt.actual, tt.value)
+					assert.NotNil(t, err, function)
+					continue
+				}
+				expected := false
+				for _, f := range tt.matching {
+					expected = expected || f == function
+				}
+				result, err := EvaluateCondition(&column, function, tt.actual, tt.value)
+				assert.Nil(t, err)
+				assert.Equal(t, expected, result, function)
+			}
+		})
+	}
+
+	var column ColumnSchema
+	assert.Nil(t, json.Unmarshal([]byte(`{"type": "string"}`), &column))
+	_, err := EvaluateCondition(&column, ConditionEqual, nil, "foo")
+	assert.NotNil(t, err)
+	_, err = EvaluateCondition(&column, ConditionEqual, 42, "foo")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,270 @@
+package ovsdb
+
+import (
+	"fmt"
+	"reflect"
+	"unicode/utf8"
+)
+
+// ErrConstraintViolation describes a value that does not satisfy the constraints of its column
+type ErrConstraintViolation struct {
+	table  string
+	column string
+	value  interface{}
+	reason string
+}
+
+func (e *ErrConstraintViolation) Error() string {
+	return fmt.Sprintf("table %s, column %s: invalid value %v: %s", e.table, e.column, e.value, e.reason)
+}
+
+// NewErrConstraintViolation creates a new ErrConstraintViolation
+func NewErrConstraintViolation(table, column string, value interface{}, reason string) error {
+	return &ErrConstraintViolation{
+		table:  table,
+		column: column,
+		value:  value,
+		reason: reason,
+	}
+}
+
+// ValidateValue checks that a native value satisfies the constraints of the column: the number
+// of elements of sets and maps and, for every key and value, the enum, the range of integers and
+// reals and the length of strings of its base type
+func ValidateValue(column *ColumnSchema, nativeValue interface{}) error {
+	if NativeType(column) != reflect.TypeOf(nativeValue) {
+		return NewErrWrongType(fmt.Sprintf("Value of column %s", column), NativeType(column).String(), nativeValue)
+	}
+	if column.TypeObj == nil {
+		// atomic columns without constraints
+		return nil
+	}
+	value := reflect.ValueOf(nativeValue)
+	switch column.Type {
+	case TypeSet:
+		if err := validateSize(column.TypeObj, value.Len()); err != nil {
+			return err
+		}
+		for i := 0; i < value.Len(); i++ {
+			if err := validateAtom(column.TypeObj.Key, value.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+	case TypeMap:
+		if err := validateSize(column.TypeObj, value.Len()); err != nil {
+			return err
+		}
+		iter := value.MapRange()
+		for iter.Next() {
+			if err := validateAtom(column.TypeObj.Key, iter.Key().Interface()); err != nil {
+				return fmt.Errorf("key %v: %v", iter.Key().Interface(), err)
+			}
+			if err := validateAtom(column.TypeObj.Value, iter.Value().Interface()); err != nil {
+				return fmt.Errorf("value of key %v: %v", iter.Key().Interface(), err)
+			}
+		}
+	default:
+		return validateAtom(column.TypeObj.Key, nativeValue)
+	}
+	return nil
+}
+
+// validateSize checks the number of elements of a set or a map
+func validateSize(columnType *ColumnType, size int) error {
+	if size < columnType.Min() {
+		return fmt.Errorf("%d elements, at least %d required", size, columnType.Min())
+	}
+	if columnType.Max() != Unlimited && size > columnType.Max() {
+		return fmt.Errorf("%d elements, at most %d allowed", size, columnType.Max())
+	}
+	return nil
+}
+
+// validateAtom checks that a native atomic value satisfies the constraints of its base type
+func validateAtom(baseType *BaseType, atom interface{}) error {
+	if baseType == nil {
+		return nil
+	}
+	if len(baseType.Enum) > 0 {
+		found := false
+		for _, enum := range baseType.Enum {
+			nativeEnum, err := OvsToNativeAtomic(baseType.Type, enum)
+			if err == nil && nativeEnum == atom {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%v is not one of %v", atom, baseType.Enum)
+		}
+	}
+	switch v := atom.(type) {
+	case int:
+		if baseType.MinInteger != nil && v < *baseType.MinInteger {
+			return fmt.Errorf("%d is less than the minimum %d", v, *baseType.MinInteger)
+		}
+		if baseType.MaxInteger != nil && v > *baseType.MaxInteger {
+			return fmt.Errorf("%d is greater than the maximum %d", v, *baseType.MaxInteger)
+		}
+	case float64:
+		if baseType.MinReal != nil && v < *baseType.MinReal {
+			return fmt.Errorf("%g is less than the minimum %g", v, *baseType.MinReal)
+		}
+		if baseType.MaxReal != nil && v > *baseType.MaxReal {
+			return fmt.Errorf("%g is greater than the maximum %g", v, *baseType.MaxReal)
+		}
+	case string:
+		if baseType.Type != TypeString {
+			return nil
+		}
+		// lengths are measured in characters
+		length := utf8.RuneCountInString(v)
+		if baseType.MinLength != nil && length < *baseType.MinLength {
+			return fmt.Errorf("%q is shorter than the minimum length %d", v, *baseType.MinLength)
+		}
+		if baseType.MaxLength != nil && length > *baseType.MaxLength {
+			return fmt.Errorf("%q is longer than the maximum length %d", v, *baseType.MaxLength)
+		}
+	}
+	return nil
+}
+
+// validateRowValues checks the values, in OVSDB notation, of the columns of a row
+func validateRowValues(tableName string, table *TableSchema, row map[string]interface{}) error {
+	for name, ovsValue := range row {
+		column, ok := table.Columns[name]
+		if !ok {
+			if name != "_uuid" && name != "_version" {
+				return fmt.Errorf("table %s: unknown column %s", tableName, name)
+			}
+			continue
+		}
+		nativeValue, err := OvsToNative(column, ovsValue)
+		if err != nil {
+			return NewErrConstraintViolation(tableName, name, ovsValue, err.Error())
+		}
+		if err := ValidateValue(column, nativeValue); err != nil {
+			return NewErrConstraintViolation(tableName, name, ovsValue, err.Error())
+		}
+	}
+	return nil
+}
+
+// validateConditionValues checks the values, in OVSDB notation, of a list of conditions
+// The sets and maps of includes and excludes conditions can have any number of elements
+func validateConditionValues(tableName string, table *TableSchema, conditions []Condition) error {
+	for _, cond := range conditions {
+		column := table.Column(cond.Column)
+		if cond.Column == "_version" {
+			column = &UUIDColumn
+		}
+		if column == nil {
+			return fmt.Errorf("table %s: unknown column %s in condition", tableName, cond.Column)
+		}
+		if cond.Function == ConditionIncludes || cond.Function == ConditionExcludes {
+			column = relaxedSize(column)
+		}
+		nativeValue, err := OvsToNative(column, cond.Value)
+		if err == nil {
+			err = ValidateCondition(column, cond.Function, nativeValue)
+		}
+		if err == nil {
+			err = ValidateValue(column, nativeValue)
+		}
+		if err != nil {
+			return NewErrConstraintViolation(tableName, cond.Column, cond.Value, err.Error())
+		}
+	}
+	return nil
+}
+
+// validateMutationValues checks the mutations, in OVSDB notation, of a mutate operation
+// The elements inserted in sets and maps must satisfy the constraints of their base types,
+// but the size of the resulting set or map can not be known beforehand
+func validateMutationValues(tableName string, table *TableSchema, mutations []interface{}) error {
+	for _, m := range mutations {
+		mutation, ok := m.([]interface{})
+		if !ok || len(mutation) != 3 {
+			return fmt.Errorf("table %s: invalid mutation %v", tableName, m)
+		}
+		name, ok := mutation[0].(string)
+		if !ok {
+			return fmt.Errorf("table %s: invalid mutation %v", tableName, m)
+		}
+		column, ok := table.Columns[name]
+		if !ok {
+			return fmt.Errorf("table %s: unknown column %s in mutation", tableName, name)
+		}
+		mutator, ok := mutation[1].(Mutator)
+		if !ok {
+			if s, isString := mutation[1].(string); isString {
+				mutator = Mutator(s)
+			}
+		}
+		if err := validateMutation(column, mutator, mutation[2]); err != nil {
+			return NewErrConstraintViolation(tableName, name, mutation[2], err.Error())
+		}
+	}
+	return nil
+}
+
+// validateMutation checks a mutation of a column. Deleting from a set or a map can not break
+// the constraints of its elements, so only the type of the value is checked
+func validateMutation(column *ColumnSchema, mutator Mutator, ovsValue interface{}) error {
+	switch mutator {
+	case MutateOperationInsert, MutateOperationDelete:
+		relaxed := relaxedSize(column)
+		if column.Type == TypeMap && mutator == MutateOperationDelete {
+			// keys can be deleted from a map with a set of keys
+			keys := &ColumnSchema{Type: TypeSet, TypeObj: &ColumnType{Key: column.TypeObj.Key, min: &zero, max: &Unlimited}}
+			if _, err := OvsToNative(keys, ovsValue); err == nil {
+				return nil
+			}
+		}
+		nativeValue, err := OvsToNative(relaxed, ovsValue)
+		if err != nil {
+			return err
+		}
+		if err := ValidateMutation(relaxed, mutator, nativeValue); err != nil {
+			return err
+		}
+		if mutator == MutateOperationDelete {
+			return nil
+		}
+		return ValidateValue(relaxed, nativeValue)
+	default:
+		atomicType := column.Type
+		if column.TypeObj != nil && column.TypeObj.Key != nil {
+			atomicType = column.TypeObj.Key.Type
+		}
+		if !isAtomicType(atomicType) {
+			return fmt.Errorf("wrong mutator %s for column %s", mutator, column)
+		}
+		nativeValue, err := OvsToNativeAtomic(atomicType, ovsValue)
+		if err != nil {
+			return err
+		}
+		if err := ValidateMutation(column, mutator, nativeValue); err != nil {
+			return err
+		}
+		if (mutator == MutateOperationDivide || mutator == MutateOperationModulo) && reflect.ValueOf(nativeValue).IsZero() {
+			return fmt.Errorf("division by zero")
+		}
+		return nil
+	}
+}
+
+var zero = 0
+
+// relaxedSize returns a copy of the column that can hold sets and maps of any size
+func relaxedSize(column *ColumnSchema) *ColumnSchema {
+	if column.TypeObj == nil || (column.Type != TypeSet && column.Type != TypeMap) {
+		return column
+	}
+	relaxed := *column
+	columnType := *column.TypeObj
+	columnType.min = &zero
+	columnType.max = &Unlimited
+	relaxed.TypeObj = &columnType
+	return &relaxed
+}
@@ -0,0 +1,224 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var constraintsTestSchema = []byte(`{"name": "TestSchema",
+	  "version": "0.0.0",
+	  "tables": {
+	    "test": {
+	      "columns": {
+	        "name": {"type": {"key": {"type": "string", "minLength": 1, "maxLength": 4}}},
+	        "mode": {"type": {"key": {"type": "string", "enum": ["set", ["active", "standby"]]}}},
+	        "priority": {"type": {"key": {"type": "integer", "minInteger": 0, "maxInteger": 10}}},
+	        "weight": {"type": {"key": {"type": "real", "minReal": 0.5, "maxReal": 1.5}}},
+	        "tags": {"type": {"key": {"type": "integer", "minInteger": 1, "maxInteger": 4095}, "min": 0, "max": 2}},
+	        "protocols": {"type": {"key": {"type": "string", "enum": ["set", ["OpenFlow10", "OpenFlow15"]]}, "min": 1, "max": "unlimited"}},
+	        "options": {"type": {"key": {"type": "string", "maxLength": 3}, "value": {"type": "integer", "maxInteger": 5}, "min": 0, "max": "unlimited"}},
+	        "flags": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}, "mutable": false}
+	      }
+	    }
+	  }
+	}`)
+
+func constraintsTestTable(t *testing.T) (DatabaseSchema, TableSchema) {
+	var schema DatabaseSchema
+	require.Nil(t, json.Unmarshal(constraintsTestSchema, &schema))
+	return schema, schema.Tables["test"]
+}
+
+func testSet(t *testing.T, elems interface{}) OvsSet {
+	set, err := NewOvsSet(elems)
+	require.Nil(t, err)
+	return *set
+}
+
+func testMap(t *testing.T, elems interface{}) OvsMap {
+	m, err := NewOvsMap(elems)
+	require.Nil(t, err)
+	return *m
+}
+
+func TestValidateValue(t *testing.T) {
+	_, table := constraintsTestTable(t)
+	tests := []struct {
+		name   string
+		column string
+		value  interface{}
+		valid  bool
+	}{
+		{"string length", "name", "ab", true},
+		{"string too short", "name", "", false},
+		{"string too long", "name", "abcde", false},
+		{"string length in characters", "name", "ñañá", true},
+		{"enum", "mode", "active", true},
+		{"not in enum", "mode", "passive", false},
+		{"integer range", "priority", 10, true},
+		{"integer too small", "priority", -1, false},
+		{"integer too big", "priority", 11, false},
+		{"real range", "weight", 0.5, true},
+		{"real too small", "weight", 0.4, false},
+		{"real too big", "weight", 1.6, false},
+		{"empty set", "tags", []int{}, true},
+		{"set", "tags", []int{1, 4095}, true},
+		{"set too big", "tags", []int{1, 2, 3}, false},
+		{"set element out of range", "tags", []int{0}, false},
+		{"set too small", "protocols", []string{}, false},
+		{"set element not in enum", "protocols", []string{"OpenFlow10", "OpenFlow11"}, false},
+		{"map", "options", map[string]int{"foo": 5}, true},
+		{"map key too long", "options", map[string]int{"fooo": 1}, false},
+		{"map value out of range", "options", map[string]int{"foo": 6}, false},
+		{"wrong type", "priority", "1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateValue(table.Columns[tt.column], tt.value)
+			if tt.valid {
+				assert.Nil(t, err)
+			} else {
+				assert.NotNil(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckOperations(t *testing.T) {
+	schema, _ := constraintsTestTable(t)
+	tests := []struct {
+		name  string
+		op    Operation
+		valid bool
+	}{
+		{
+			"valid row",
+			Operation{Op: "insert", Table: "test", Row: map[string]interface{}{"name": "a", "tags": testSet(t, []int{1, 2})}},
+			true,
+		},
+		{
+			"invalid row",
+			Operation{Op: "insert", Table: "test", Row: map[string]interface{}{"mode": "passive"}},
+			false,
+		},
+		{
+			"invalid wait row",
+			Operation{Op: "wait", Table: "test", Until: "==", Columns: []string{"priority"}, Rows: []map[string]interface{}{{"priority": 20}}},
+			false,
+		},
+		{
+			"row value of the wrong type",
+			Operation{Op: "insert", Table: "test", Row: map[string]interface{}{"tags": "foo"}},
+			false,
+		},
+		{
+			"valid condition",
+			Operation{Op: "select", Table: "test", Where: []Condition{NewCondition("priority", ConditionGreaterThan, 3)}},
+			true,
+		},
+		{
+			"invalid condition",
+			Operation{Op: "select", Table: "test", Where: []Condition{NewCondition("mode", ConditionEqual, "passive")}},
+			false,
+		},
+		{
+			"invalid condition function",
+			Operation{Op: "select", Table: "test", Where: []Condition{NewCondition("name", ConditionGreaterThan, "a")}},
+			false,
+		},
+		{
+			"includes condition of any size",
+			Operation{Op: "select", Table: "test", Where: []Condition{NewCondition("protocols", ConditionIncludes, testSet(t, []string{}))}},
+			true,
+		},
+		{
+			"equal condition of invalid size",
+			Operation{Op: "select", Table: "test", Where: []Condition{NewCondition("protocols", ConditionEqual, testSet(t, []string{}))}},
+			false,
+		},
+		{
+			"version condition",
+			Operation{Op: "select", Table: "test", Where: []Condition{NewCondition("_version", ConditionEqual, UUID{GoUUID: "2f77b348-9768-4866-b761-89d5177ecda0"})}},
+			true,
+		},
+		{
+			"insert mutation",
+			Operation{Op: "mutate", Table: "test", Mutations: []interface{}{NewMutation("tags", string(MutateOperationInsert), testSet(t, []int{1, 2, 3}))}},
+			true,
+		},
+		{
+			"insert mutation out of range",
+			Operation{Op: "mutate", Table: "test", Mutations: []interface{}{NewMutation("tags", string(MutateOperationInsert), testSet(t, []int{5000}))}},
+			false,
+		},
+		{
+			"insert mutation of an invalid map value",
+			Operation{Op: "mutate", Table: "test", Mutations: []interface{}{NewMutation("options", string(MutateOperationInsert), testMap(t, map[string]int{"foo": 6}))}},
+			false,
+		},
+		{
+			"delete mutation",
+			Operation{Op: "mutate", Table: "test", Mutations: []interface{}{NewMutation("tags", string(MutateOperationDelete), testSet(t, []int{5000}))}},
+			true,
+		},
+		{
+			"delete mutation of map keys",
+			Operation{Op: "mutate", Table: "test", Mutations: []interface{}{NewMutation("options", string(MutateOperationDelete), testSet(t, []string{"foo"}))}},
+			true,
+		},
+		{
+			"arithmetic mutation",
+			Operation{Op: "mutate", Table: "test", Mutations: []interface{}{NewMutation("priority", string(MutateOperationAdd), 1)}},
+			true,
+		},
+		{
+			"division by zero",
+			Operation{Op: "mutate", Table: "test", Mutations: []interface{}{NewMutation("priority", string(MutateOperationDivide), 0)}},
+			false,
+		},
+		{
+			"invalid mutator",
+			Operation{Op: "mutate", Table: "test", Mutations: []interface{}{NewMutation("name", string(MutateOperationAdd), "a")}},
+			false,
+		},
+		{
+			"immutable column",
+			Operation{Op: "mutate", Table: "test", Mutations: []interface{}{NewMutation("flags", string(MutateOperationInsert), testMap(t, map[string]string{"a": "b"}))}},
+			false,
+		},
+		{
+			"unknown mutation column",
+			Operation{Op: "mutate", Table: "test", Mutations: []interface{}{NewMutation("foo", string(MutateOperationAdd), 1)}},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.CheckOperations(tt.op)
+			if tt.valid {
+				assert.Nil(t, err)
+			} else {
+				assert.NotNil(t, err)
+			}
+			assert.Equal(t, tt.valid, schema.ValidateOperations(tt.op))
+		})
+	}
+}
+
+func TestCheckOperationsError(t *testing.T) {
+	schema, _ := constraintsTestTable(t)
+	err := schema.CheckOperations(
+		Operation{Op: "insert", Table: "test", Row: map[string]interface{}{"name": "a"}},
+		Operation{Op: "insert", Table: "test", Row: map[string]interface{}{"mode": "passive"}},
+	)
+	var violation *ErrConstraintViolation
+	require.True(t, errors.As(err, &violation))
+	assert.Contains(t, err.Error(), "operation 1")
+	assert.Contains(t, err.Error(), "table test")
+	assert.Contains(t, err.Error(), "column mode")
+	assert.Contains(t, err.Error(), "passive")
+}
@@ -14,11 +14,21 @@ type Operation struct {
 	Where     []Condition              `json:"where,omitempty"`
 	Until     string                   `json:"until,omitempty"`
 	UUIDName  string                   `json:"uuid-name,omitempty"`
+	Comment   string                   `json:"comment,omitempty"`
+	Lock      string                   `json:"lock,omitempty"`
 }
 
 // MarshalJSON marshalls 'Operation' to a byte array
 // For 'select' operations, we dont omit the 'Where' field
 // to allow selecting all rows of a table
+// For 'wait' operations, we dont omit the 'Timeout' field
+// as a missing timeout makes the server wait forever, nor the
+// 'Where', 'Columns' and 'Rows' fields as they are required and
+// an empty list of rows waits until no row matches
+// For 'mutate' operations, we dont omit the 'Mutations' field
+// as an empty list is valid and only counts the matching rows
+// For 'comment', 'assert' and 'abort' operations, we omit the
+// 'Table' field as they do not apply to a table
 func (o Operation) MarshalJSON() ([]byte, error) {
 	type OpAlias Operation
 	switch o.Op {
@@ -34,6 +44,51 @@ func (o Operation) MarshalJSON() ([]byte, error) {
 			Where:   where,
 			OpAlias: (OpAlias)(o),
 		})
+	case "mutate":
+		mutations := o.Mutations
+		if mutations == nil {
+			mutations = make([]interface{}, 0)
+		}
+		return json.Marshal(&struct {
+			Mutations []interface{} `json:"mutations"`
+			OpAlias
+		}{
+			Mutations: mutations,
+			OpAlias:   (OpAlias)(o),
+		})
+	case "comment", "assert", "abort":
+		return json.Marshal(&struct {
+			Table string `json:"table,omitempty"`
+			OpAlias
+		}{
+			OpAlias: (OpAlias)(o),
+		})
+	case "wait":
+		where := o.Where
+		if where == nil {
+			where = make([]Condition, 0)
+		}
+		columns := o.Columns
+		if columns == nil {
+			columns = make([]string, 0)
+		}
+		rows := o.Rows
+		if rows == nil {
+			rows = make([]map[string]interface{}, 0)
+		}
+		return json.Marshal(&struct {
+			Timeout int                      `json:"timeout"`
+			Where   []Condition              `json:"where"`
+			Columns []string                 `json:"columns"`
+			Rows    []map[string]interface{} `json:"rows"`
+			OpAlias
+		}{
+			Timeout: o.Timeout,
+			Where:   where,
+			Columns: columns,
+			Rows:    rows,
+			OpAlias: (OpAlias)(o),
+		})
 	default:
 		return json.Marshal(&struct {
 			OpAlias
@@ -52,11 +107,36 @@ type MonitorRequests struct {
 }
 
 // MonitorRequest represents a monitor request according to RFC7047
+// Where is only used by monitor_cond requests (ovsdb-server(7) section 4.1.12).
+// A row is monitored if it matches any of the conditions. If no conditions are
+// given, every row is monitored
 type MonitorRequest struct {
 	Columns []string       `json:"columns,omitempty"`
+	Where   []Condition    `json:"where,omitempty"`
 	Select  *MonitorSelect `json:"select,omitempty"`
 }
 
+// MonitorCondChangeRequest represents a monitor-cond-update-request according to
+// ovsdb-server(7) section 4.1.13. Where replaces the conditions of the table.
+// An empty list of conditions monitors every row
+type MonitorCondChangeRequest struct {
+	Where []Condition `json:"where"`
+}
+
+// MarshalJSON marshalls 'MonitorCondChangeRequest' to a byte array
+// A nil Where is sent as an empty list of conditions
+func (m MonitorCondChangeRequest) MarshalJSON() ([]byte, error) {
+	where := m.Where
+	if where == nil {
+		where = make([]Condition, 0)
+	}
+	return json.Marshal(&struct {
+		Where []Condition `json:"where"`
+	}{
+		Where: where,
+	})
+}
+
 // TableUpdates is a collection of TableUpdate entries
 // We cannot use TableUpdates directly by json encoding by inlining the TableUpdate Map
 // structure till GoLang issue #6213 makes it.
@@ -77,6 +157,58 @@ type RowUpdate struct {
 	Old Row `json:"old,omitempty"`
 }
 
+// TableUpdates2 is a collection of TableUpdate2 entries as received in update2
+// notifications and in the reply to monitor_cond requests
+type TableUpdates2 struct {
+	Updates map[string]TableUpdate2 `json:"updates"`
+}
+
+// TableUpdate2 represents a table update according to ovsdb-server(7) section 4.1.14
+type TableUpdate2 struct {
+	Rows map[string]RowUpdate2 `json:"rows"`
+}
+
+// RowUpdate2 represents a row update according to ovsdb-server(7) section 4.1.14
+// Exactly one of its members is set:
+// Initial and Insert hold the contents of a row that is monitored for the first time
+// or that has been inserted, Modify holds the difference between the old and the new
+// contents of a modified row and Delete is true if the row has been deleted.
+// The difference in Modify contains, for scalar columns and sets with at most one element,
+// the new value. For other sets, the elements that have been added or removed and
+// for maps, the pairs that have been added, removed or whose value has been changed
+type RowUpdate2 struct {
+	Initial *Row `json:"initial,omitempty"`
+	Insert  *Row `json:"insert,omitempty"`
+	Modify  *Row `json:"modify,omitempty"`
+	Delete  bool `json:"-"`
+}
+
+// MarshalJSON marshalls 'RowUpdate2' to a byte array
+func (r RowUpdate2) MarshalJSON() ([]byte, error) {
+	if r.Delete {
+		return []byte(`{"delete":null}`), nil
+	}
+	type RowUpdate2Alias RowUpdate2
+	return json.Marshal(RowUpdate2Alias(r))
+}
+
+// UnmarshalJSON unmarshalls a byte array to a 'RowUpdate2'
+// The "delete" member has a null value so its presence has to be checked explicitly
+func (r *RowUpdate2) UnmarshalJSON(b []byte) error {
+	type RowUpdate2Alias RowUpdate2
+	var alias RowUpdate2Alias
+	if err := json.Unmarshal(b, &alias); err != nil {
+		return err
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(b, &members); err != nil {
+		return err
+	}
+	_, alias.Delete = members["delete"]
+	*r = RowUpdate2(alias)
+	return nil
+}
+
 // OvsdbError is an OVS Error Condition
 type OvsdbError struct {
 	Error   string `json:"error"`
@@ -130,6 +262,14 @@ func ovsSliceToGoNotation(val interface{}) (interface{}, error) {
 	return val, nil
 }
 
+// WaitCondition is the condition a wait operation waits for (RFC 7047 5.2.6)
+type WaitCondition string
+
+const (
+	WaitConditionEqual    WaitCondition = "=="
+	WaitConditionNotEqual WaitCondition = "!="
+)
+
 type Mutator string
 
 const (
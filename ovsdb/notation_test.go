@@ -59,6 +59,61 @@ func TestOpRowsSerialization(t *testing.T) {
 	}
 }
 
+func TestOpWaitSerialization(t *testing.T) {
+	operation := Operation{
+		Op:      "wait",
+		Table:   "Bridge",
+		Where:   []Condition{NewCondition("name", ConditionEqual, "br0")},
+		Columns: []string{"name"},
+		Until:   "==",
+		Rows:    []map[string]interface{}{{"name": "br0"}},
+	}
+
+	str, err := json.Marshal(operation)
+
+	if err != nil {
+		log.Fatal("serialization error:", err)
+	}
+
+	expected := `{"timeout":0,"where":[["name","==","br0"]],"columns":["name"],"rows":[{"name":"br0"}],"op":"wait","table":"Bridge","until":"=="}`
+
+	if string(str) != expected {
+		t.Error("Expected: ", expected, "Got", string(str))
+	}
+
+	// waiting until no row matches
+	operation.Rows = nil
+	str, err = json.Marshal(operation)
+	if err != nil {
+		log.Fatal("serialization error:", err)
+	}
+	expected = `{"timeout":0,"where":[["name","==","br0"]],"columns":["name"],"rows":[],"op":"wait","table":"Bridge","until":"=="}`
+	if string(str) != expected {
+		t.Error("Expected: ", expected, "Got", string(str))
+	}
+}
+
+func TestOpTablelessSerialization(t *testing.T) {
+	tests := []struct {
+		operation Operation
+		expected  string
+	}{
+		{Operation{Op: "comment", Comment: "hello"}, `{"op":"comment","comment":"hello"}`},
+		{Operation{Op: "assert", Lock: "lock"}, `{"op":"assert","lock":"lock"}`},
+		{Operation{Op: "abort"}, `{"op":"abort"}`},
+		{Operation{Op: "mutate", Table: "Bridge"}, `{"mutations":[],"op":"mutate","table":"Bridge"}`},
+	}
+	for _, tt := range tests {
+		str, err := json.Marshal(tt.operation)
+		if err != nil {
+			log.Fatal("serialization error:", err)
+		}
+		if string(str) != tt.expected {
+			t.Error("Expected: ", tt.expected, "Got", string(str))
+		}
+	}
+}
+
 func TestValidateOvsSet(t *testing.T) {
 	goSlice := []int{1, 2, 3, 4}
 	oSet, err := NewOvsSet(goSlice)
@@ -164,3 +219,41 @@ func TestNewMutation(t *testing.T) {
 		t.Error("mutation is not correctly formatted")
 	}
 }
+
+func TestRowUpdate2Serialization(t *testing.T) {
+	var updates map[string]RowUpdate2
+	err := json.Unmarshal([]byte(`{
+		"a": {"initial": {"name": "br0"}},
+		"b": {"insert": {"name": "br1"}},
+		"c": {"modify": {"external_ids": ["map", [["foo", "bar"]]]}},
+		"d": {"delete": null}
+	}`), &updates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updates["a"].Initial == nil || updates["a"].Initial.Fields["name"] != "br0" {
+		t.Error("initial row is not correctly parsed")
+	}
+	if updates["b"].Insert == nil || updates["b"].Insert.Fields["name"] != "br1" {
+		t.Error("insert row is not correctly parsed")
+	}
+	if updates["c"].Modify == nil {
+		t.Error("modify row is not correctly parsed")
+	} else if m, ok := updates["c"].Modify.Fields["external_ids"].(OvsMap); !ok || m.GoMap["foo"] != "bar" {
+		t.Error("modify row is not correctly parsed")
+	}
+	for uuid, update := range updates {
+		if update.Delete != (uuid == "d") {
+			t.Errorf("delete of row %s is not correctly parsed", uuid)
+		}
+	}
+
+	deleteStr, _ := json.Marshal(RowUpdate2{Delete: true})
+	if string(deleteStr) != `{"delete":null}` {
+		t.Error("Expected: ", `{"delete":null}`, " Got: ", string(deleteStr))
+	}
+	insertStr, _ := json.Marshal(RowUpdate2{Insert: &Row{Fields: map[string]interface{}{"name": "br1"}}})
+	if string(insertStr) != `{"insert":{"name":"br1"}}` {
+		t.Error("Expected: ", `{"insert":{"name":"br1"}}`, " Got: ", string(insertStr))
+	}
+}
@@ -22,6 +22,11 @@ func (r *Row) UnmarshalJSON(b []byte) (err error) {
 	return err
 }
 
+// MarshalJSON marshalls an OVSDB Row to a byte array
+func (r Row) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Fields)
+}
+
 // ResultRow is an properly unmarshalled row returned by Transact
 type ResultRow map[string]interface{}
 
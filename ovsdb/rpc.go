@@ -29,6 +29,16 @@ func NewMonitorArgs(database string, value interface{}, requests map[string]Moni
 	return []interface{}{database, value, requests}
 }
 
+// NewMonitorCondSinceArgs creates a new set of arguments for a monitor_cond_since RPC
+func NewMonitorCondSinceArgs(database string, value interface{}, requests map[string]MonitorRequest, lastTransactionID string) []interface{} {
+	return []interface{}{database, value, requests, lastTransactionID}
+}
+
+// NewMonitorCondChangeArgs creates a new set of arguments for a monitor_cond_change RPC
+func NewMonitorCondChangeArgs(value, newValue interface{}, requests map[string][]MonitorCondChangeRequest) []interface{} {
+	return []interface{}{value, newValue, requests}
+}
+
 // NewMonitorCancelArgs creates a new set of arguments for a monitor_cancel RPC
 func NewMonitorCancelArgs(value interface{}) []interface{} {
 	return []interface{}{value}
@@ -55,3 +65,18 @@ type NotificationHandler interface {
 
 	Disconnected()
 }
+
+// Update2Handler can be implemented by a NotificationHandler to receive the notifications
+// of the monitors issued with monitor_cond and, unless it implements Update3Handler,
+// monitor_cond_since
+type Update2Handler interface {
+	// ovsdb-server(7) section 4.1.14 Update2 Notification
+	Update2(context interface{}, tableUpdates TableUpdates2)
+}
+
+// Update3Handler can be implemented by a NotificationHandler to receive the notifications
+// of the monitors issued with monitor_cond_since along with the id of their transaction
+type Update3Handler interface {
+	// ovsdb-server(7) section 4.1.16 Update3 Notification
+	Update3(context interface{}, lastTxnID string, tableUpdates TableUpdates2)
+}
@@ -85,3 +85,28 @@ func TestNewLockArgs(t *testing.T) {
 		t.Error("Expected: ", expected, " Got: ", string(argString))
 	}
 }
+
+func TestNewMonitorCondChangeArgs(t *testing.T) {
+	requests := map[string][]MonitorCondChangeRequest{
+		"Bridge": {{Where: []Condition{NewCondition("name", ConditionEqual, "br0")}}},
+		"Port":   {{}},
+	}
+	args := NewMonitorCondChangeArgs(1, 2, requests)
+	argString, _ := json.Marshal(args)
+	expected := `[1,2,{"Bridge":[{"where":[["name","==","br0"]]}],"Port":[{"where":[]}]}]`
+	if string(argString) != expected {
+		t.Error("Expected: ", expected, " Got: ", string(argString))
+	}
+}
+
+func TestNewMonitorCondSinceArgs(t *testing.T) {
+	requests := map[string]MonitorRequest{
+		"Bridge": {Columns: []string{"name"}, Where: []Condition{NewCondition("name", ConditionEqual, "br0")}},
+	}
+	args := NewMonitorCondSinceArgs("Open_vSwitch", 1, requests, "00000000-0000-0000-0000-000000000000")
+	argString, _ := json.Marshal(args)
+	expected := `["Open_vSwitch",1,{"Bridge":{"columns":["name"],"where":[["name","==","br0"]]}},"00000000-0000-0000-0000-000000000000"]`
+	if string(argString) != expected {
+		t.Error("Expected: ", expected, " Got: ", string(argString))
+	}
+}
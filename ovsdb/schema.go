@@ -61,38 +61,57 @@ func SchemaFromFile(f *os.File) (*DatabaseSchema, error) {
 }
 
 // ValidateOperations performs basic validation for operations against a DatabaseSchema
+// Operations that do not apply to a table, like comment, assert and abort, must have
+// the fields they require and wait operations a valid condition
 func (schema DatabaseSchema) ValidateOperations(operations ...Operation) bool {
-	for _, op := range operations {
-		table, ok := schema.Tables[op.Table]
-		if ok {
-			for column := range op.Row {
-				if _, ok := table.Columns[column]; !ok {
-					if column != "_uuid" && column != "_version" {
-						return false
-					}
-				}
+	return schema.CheckOperations(operations...) == nil
+}
+
+// CheckOperations validates operations against a DatabaseSchema like ValidateOperations
+// does and checks that the values of their rows, conditions and mutations satisfy the
+// constraints of their columns. It returns the first problem found
+func (schema DatabaseSchema) CheckOperations(operations ...Operation) error {
+	for i, op := range operations {
+		switch op.Op {
+		case "comment", "abort":
+			continue
+		case "assert":
+			if op.Lock == "" {
+				return fmt.Errorf("operation %d: assert requires a lock", i)
 			}
-			for _, row := range op.Rows {
-				for column := range row {
-					if _, ok := table.Columns[column]; !ok {
-						if column != "_uuid" && column != "_version" {
-							return false
-						}
-					}
-				}
+			continue
+		case "wait":
+			if op.Until != string(WaitConditionEqual) && op.Until != string(WaitConditionNotEqual) {
+				return fmt.Errorf("operation %d: invalid wait condition %s", i, op.Until)
+			}
+		}
+		table, ok := schema.Tables[op.Table]
+		if !ok {
+			return fmt.Errorf("operation %d: unknown table %s", i, op.Table)
+		}
+		if err := validateRowValues(op.Table, &table, op.Row); err != nil {
+			return fmt.Errorf("operation %d: %w", i, err)
+		}
+		for _, row := range op.Rows {
+			if err := validateRowValues(op.Table, &table, row); err != nil {
+				return fmt.Errorf("operation %d: %w", i, err)
 			}
-			for _, column := range op.Columns {
-				if _, ok := table.Columns[column]; !ok {
-					if column != "_uuid" && column != "_version" {
-						return false
-					}
+		}
+		for _, column := range op.Columns {
+			if _, ok := table.Columns[column]; !ok {
+				if column != "_uuid" && column != "_version" {
+					return fmt.Errorf("operation %d: table %s: unknown column %s", i, op.Table, column)
 				}
 			}
-		} else {
-			return false
+		}
+		if err := validateConditionValues(op.Table, &table, op.Where); err != nil {
+			return fmt.Errorf("operation %d: %w", i, err)
+		}
+		if err := validateMutationValues(op.Table, &table, op.Mutations); err != nil {
+			return fmt.Errorf("operation %d: %w", i, err)
 		}
 	}
-	return true
+	return nil
 }
 
 // TableSchema is a table schema according to RFC7047
@@ -228,7 +247,7 @@ func (b *BaseType) UnmarshalJSON(data []byte) error {
 	b.MaxReal = bt.MaxReal
 	b.MinInteger = bt.MinInteger
 	b.MaxInteger = bt.MaxInteger
-	b.MinLength = bt.MaxLength
+	b.MinLength = bt.MinLength
 	b.MaxLength = bt.MaxLength
 	b.RefTable = bt.RefTable
 	b.RefType = bt.RefType
@@ -254,7 +273,7 @@ func (b BaseType) MarshalJSON() ([]byte, error) {
 		MaxReal:    b.MaxReal,
 		MinInteger: b.MinInteger,
 		MaxInteger: b.MaxInteger,
-		MinLength:  b.MaxLength,
+		MinLength:  b.MinLength,
 		MaxLength:  b.MaxLength,
 		RefTable:   b.RefTable,
 		RefType:    b.RefType,
@@ -405,6 +405,47 @@ func TestTable(t *testing.T) {
 	})
 }
 
+func TestValidateOperations(t *testing.T) {
+	schemaJ := []byte(`{"name": "TestSchema",
+		  "version": "0.0.0",
+		  "tables": {
+		    "test": {
+		      "columns": {
+		        "foo": {"type": "string"}
+		      }
+		    }
+		}
+	    }`)
+
+	var schema DatabaseSchema
+	err := json.Unmarshal(schemaJ, &schema)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name  string
+		op    Operation
+		valid bool
+	}{
+		{"insert", Operation{Op: "insert", Table: "test", Row: map[string]interface{}{"foo": "a"}}, true},
+		{"unknown table", Operation{Op: "insert", Table: "other"}, false},
+		{"unknown column", Operation{Op: "insert", Table: "test", Row: map[string]interface{}{"bar": "a"}}, false},
+		{"select", Operation{Op: "select", Table: "test", Columns: []string{"_uuid", "foo"}}, true},
+		{"unknown condition column", Operation{Op: "select", Table: "test", Where: []Condition{NewCondition("bar", ConditionEqual, "a")}}, false},
+		{"wait", Operation{Op: "wait", Table: "test", Columns: []string{"foo"}, Until: "=="}, true},
+		{"wait without condition", Operation{Op: "wait", Table: "test", Columns: []string{"foo"}}, false},
+		{"wait invalid condition", Operation{Op: "wait", Table: "test", Columns: []string{"foo"}, Until: "<"}, false},
+		{"comment", Operation{Op: "comment", Comment: "hello"}, true},
+		{"abort", Operation{Op: "abort"}, true},
+		{"assert", Operation{Op: "assert", Lock: "lock"}, true},
+		{"assert without lock", Operation{Op: "assert"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.valid, schema.ValidateOperations(tt.op))
+		})
+	}
+}
+
 func TestBaseTypeMarshalUnmarshalJSON(t *testing.T) {
 	datapath := "Datapath"
 	zero := 0
@@ -473,6 +514,13 @@ func TestBaseTypeMarshalUnmarshalJSON(t *testing.T) {
 			[]byte(`{"type":"integer","minInteger":0,"maxInteger": 4294967295}`),
 			false,
 		},
+		{
+			"string with min and max length",
+			[]byte(`{"type":"string","minLength":0,"maxLength":4294967295}`),
+			BaseType{Type: TypeString, MinLength: &zero, MaxLength: &max},
+			[]byte(`{"type":"string","minLength":0,"maxLength":4294967295}`),
+			false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {